@@ -1,10 +1,13 @@
 // Package main implements the asset bundler for DIRELEX.
 //
 // This command uses esbuild to bundle and minify CSS and JavaScript files.
+// The search data bundled with the JavaScript is generated from the data file first,
+// so that the autocomplete always matches the loaded entries and glossary.
 // It should be run before building the server or generating the static site.
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -12,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/evanw/esbuild/pkg/api"
+	"github.com/softcatala/direlex/internal/core"
 )
 
 var browserTargets = []api.Engine{
@@ -28,6 +32,11 @@ func main() {
 		log.Fatalf("Failed to build CSS: %v", err)
 	}
 
+	err = buildSearchData()
+	if err != nil {
+		log.Fatalf("Failed to build search data: %v", err)
+	}
+
 	err = buildJS()
 	if err != nil {
 		log.Fatalf("Failed to build JS: %v", err)
@@ -57,6 +66,45 @@ func buildCSS() error {
 	})
 }
 
+func buildSearchData() error {
+	log.Println("  Building search data...")
+
+	err := core.LoadDataFromFile("data/data.json.gz")
+	if err != nil {
+		return fmt.Errorf("failed to load data: %w", err)
+	}
+
+	err = os.MkdirAll("js/data", 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	err = writeJSONFile("js/data/terms.json", core.GetSearchData())
+	if err != nil {
+		return err
+	}
+
+	return writeJSONFile("js/data/terms-glossary.json", core.GetGlossarySearchData())
+}
+
+func writeJSONFile(path string, data any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	err = encoder.Encode(data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
 func buildJS() error {
 	log.Println("  Building JavaScript...")
 
@@ -65,7 +113,7 @@ func buildJS() error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	jsFiles := []string{"search.js", "search-glossary.js"}
+	jsFiles := []string{"search.js", "search-glossary.js", "search-idioms.js", "search-text.js", "random-entry.js", "daily-entry.js"}
 
 	for _, file := range jsFiles {
 		inputPath := filepath.Join("js", file)
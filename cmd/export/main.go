@@ -0,0 +1,203 @@
+// Package main implements the bulk export command for DIRELEX.
+//
+// The command loads the data file exported from the CMS and writes the entries,
+// senses, synonyms, relations, idioms and glossary terms as tables (see
+// core.GetExportTables), one file per table, in one of these formats:
+//   - jsonl: one JSON object per line, with lists as arrays.
+//   - csv: RFC 4180 CSV with a header row, with lists as JSON arrays.
+//   - tsv: tab-separated values with a header row, with lists as JSON arrays, and
+//     tabs, newlines and backslashes escaped as \t, \n and \\.
+//
+// Lists are written as JSON arrays (e.g. ["trist","trista"]) in CSV and TSV files
+// because any separator may also appear in the words and titles of the data.
+//
+// The tei format writes instead the whole dictionary as a single TEI Lex-0 document
+// (see core.TEIDocument), direlex.tei.xml, checking that it is well-formed.
+//
+// Usage:
+//
+//	go run ./cmd/export [-format jsonl|csv|tsv|tei] [-out dir] [data file]
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+// tsvEscaper escapes the characters that cannot appear in TSV fields.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// teiFormat is the format of the TEI Lex-0 export, which is not a set of tables.
+const teiFormat = "tei"
+
+// tableWriters maps each format to the function writing a table in that format.
+var tableWriters = map[string]func(io.Writer, core.ExportTable) error{
+	"jsonl": writeJSONL,
+	"csv":   writeCSV,
+	"tsv":   writeTSV,
+}
+
+func main() {
+	format := flag.String("format", "jsonl", "output format: jsonl, csv, tsv or tei")
+	outDir := flag.String("out", "export", "output directory")
+	flag.Parse()
+
+	writeTable, ok := tableWriters[*format]
+	if !ok && *format != teiFormat {
+		log.Fatalf("Unknown format %q: use jsonl, csv, tsv or tei", *format)
+	}
+
+	path := "data/data.json.gz"
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
+	}
+	err := core.LoadDataFromFile(path)
+	if err != nil {
+		log.Fatalf("Failed to load data: %v", err)
+	}
+
+	err = os.MkdirAll(*outDir, 0o755)
+	if err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	if *format == teiFormat {
+		filePath := filepath.Join(*outDir, "direlex.tei.xml")
+		err := writeTEI(filePath)
+		if err != nil {
+			log.Fatalf("Failed to write %s: %v", filePath, err)
+		}
+		log.Printf("Wrote %d entries to %s", len(core.AllEntries), filePath)
+		return
+	}
+
+	for _, table := range core.GetExportTables() {
+		filePath := filepath.Join(*outDir, table.Name+"."+*format)
+		err := writeFile(filePath, table, writeTable)
+		if err != nil {
+			log.Fatalf("Failed to write %s: %v", filePath, err)
+		}
+		log.Printf("Wrote %d rows to %s", len(table.Rows), filePath)
+	}
+}
+
+// writeFile writes a table to a file with the given table writer.
+func writeFile(path string, table core.ExportTable, writeTable func(io.Writer, core.ExportTable) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = writeTable(w, table)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeJSONL writes each row as a JSON object with the columns as keys, in column order.
+func writeJSONL(w io.Writer, table core.ExportTable) error {
+	for _, row := range table.Rows {
+		var line []byte
+		for i, value := range row {
+			key, err := json.Marshal(table.Columns[i])
+			if err != nil {
+				return err
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("column %s: %w", table.Columns[i], err)
+			}
+			if i == 0 {
+				line = append(line, '{')
+			} else {
+				line = append(line, ',')
+			}
+			line = append(append(append(line, key...), ':'), encoded...)
+		}
+		line = append(line, "}\n"...)
+		_, err := w.Write(line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the table as CSV, with a header row.
+func writeCSV(w io.Writer, table core.ExportTable) error {
+	csvWriter := csv.NewWriter(w)
+	err := csvWriter.Write(table.Columns)
+	if err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		fields, err := formatRow(row, func(s string) string { return s })
+		if err != nil {
+			return err
+		}
+		err = csvWriter.Write(fields)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeTSV writes the table as tab-separated values, with a header row.
+func writeTSV(w io.Writer, table core.ExportTable) error {
+	_, err := io.WriteString(w, strings.Join(table.Columns, "\t")+"\n")
+	if err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		fields, err := formatRow(row, tsvEscaper.Replace)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, strings.Join(fields, "\t")+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatRow converts the values of a row to strings for CSV and TSV files, escaping
+// each value with the given function.
+func formatRow(row []any, escape func(string) string) ([]string, error) {
+	fields := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case string:
+			fields[i] = escape(v)
+		case int:
+			fields[i] = strconv.Itoa(v)
+		case []string:
+			list, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = escape(string(list))
+		default:
+			fields[i] = escape(fmt.Sprint(v))
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+var testTable = core.ExportTable{
+	Name:    "test",
+	Columns: []string{"id", "position", "title", "forms"},
+	Rows: [][]any{
+		{"trist", 1, "trist | trista", []string{"trist | trista", "trist"}},
+		{"cometes", 2, "\"a\", b\tc\nd\\e", []string{}},
+	},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	err := writeCSV(&b, testTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"id", "position", "title", "forms"},
+		{"trist", "1", "trist | trista", `["trist | trista","trist"]`},
+		{"cometes", "2", "\"a\", b\tc\nd\\e", "[]"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("writeCSV() = %q; want %q", records, want)
+	}
+
+	// Lists are read back as they were written, whatever their values contain.
+	var forms []string
+	err = json.Unmarshal([]byte(records[1][3]), &forms)
+	if err != nil || !reflect.DeepEqual(forms, testTable.Rows[0][3]) {
+		t.Errorf("list column = %q, %v; want %q", forms, err, testTable.Rows[0][3])
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	var b bytes.Buffer
+	err := writeTSV(&b, testTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id\tposition\ttitle\tforms\n" +
+		"trist\t1\ttrist | trista\t[\"trist | trista\",\"trist\"]\n" +
+		"cometes\t2\t\"a\", b\\tc\\nd\\\\e\t[]\n"
+	if b.String() != want {
+		t.Errorf("writeTSV() = %q; want %q", b.String(), want)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if n := strings.Count(line, "\t"); n != len(testTable.Columns)-1 {
+			t.Errorf("line %q has %d tabs; want %d", line, n, len(testTable.Columns)-1)
+		}
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var b bytes.Buffer
+	err := writeJSONL(&b, testTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != len(testTable.Rows) {
+		t.Fatalf("writeJSONL() wrote %d lines; want %d", len(lines), len(testTable.Rows))
+	}
+	want := `{"id":"trist","position":1,"title":"trist | trista","forms":["trist | trista","trist"]}`
+	if lines[0] != want {
+		t.Errorf("writeJSONL() line 1 = %s; want %s", lines[0], want)
+	}
+	var row map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil || row["title"] != testTable.Rows[1][2] {
+		t.Errorf("writeJSONL() line 2 = %s (%v)", lines[1], err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+// writeTEI writes the dictionary as a TEI Lex-0 document, after checking that it is
+// well-formed XML.
+func writeTEI(path string) error {
+	content, err := core.TEIDocument()
+	if err != nil {
+		return err
+	}
+	err = validateXML(content)
+	if err != nil {
+		return fmt.Errorf("invalid TEI document: %w", err)
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// validateXML checks that a document is well-formed XML, reading all of its tokens.
+// The decoder checks the nesting of elements, entities and names; repeated attributes
+// and the single root element are checked here.
+func validateXML(content []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil {
+			err = checkXMLToken(token, depth, roots)
+		}
+		if err != nil {
+			line, column := decoder.InputPos()
+			return fmt.Errorf("line %d, column %d: %w", line, column, err)
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if roots == 0 {
+		return errors.New("no root element")
+	}
+	return nil
+}
+
+// checkXMLToken checks the well-formedness constraints of a token that the decoder
+// does not check, given the depth of the token and the number of root elements before it.
+func checkXMLToken(token xml.Token, depth, roots int) error {
+	switch t := token.(type) {
+	case xml.StartElement:
+		if depth == 0 && roots > 0 {
+			return fmt.Errorf("second root element <%s>", t.Name.Local)
+		}
+		seen := make(map[xml.Name]bool, len(t.Attr))
+		for _, attr := range t.Attr {
+			if seen[attr.Name] {
+				return fmt.Errorf("repeated attribute %s in <%s>", attr.Name.Local, t.Name.Local)
+			}
+			seen[attr.Name] = true
+		}
+	case xml.CharData:
+		if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+			return errors.New("text outside the root element")
+		}
+	}
+	return nil
+}
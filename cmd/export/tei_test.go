@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestValidateXML(t *testing.T) {
+	tests := []struct {
+		xml   string
+		valid bool
+	}{
+		{`<?xml version="1.0" encoding="UTF-8"?><TEI><entry xml:id="a">a &amp; b</entry></TEI>`, true},
+		{`<TEI><entry/></TEI>`, true},
+		{``, false},
+		{`<?xml version="1.0"?>`, false},
+		{`<TEI/><TEI/>`, false},
+		{`<TEI/>text`, false},
+		{"<TEI/>\n", true},
+		{`<!-- comment --><TEI/>`, true},
+		{`<TEI><entry></TEI>`, false},
+		{`<TEI>a & b</TEI>`, false},
+		{`<TEI><entry a="1" a="2"/></TEI>`, false},
+		{`<TEI><entry xml:id="1" id="2"/></TEI>`, true},
+		{`<TEI>`, false},
+		{`<TEI></tei>`, false},
+	}
+	for _, tt := range tests {
+		err := validateXML([]byte(tt.xml))
+		if (err == nil) != tt.valid {
+			t.Errorf("validateXML(%q) = %v; want valid %v", tt.xml, err, tt.valid)
+		}
+	}
+}
@@ -4,9 +4,12 @@
 //   - Loading dictionary data from a gzipped JSON file.
 //   - Parsing HTML templates for rendering web pages.
 //   - Handling HTTP requests.
+//   - Serving the full-text search page and the JSON API, described in core.OpenAPIDocument.
+//   - Serving the OpenSearch description and browser search suggestions.
+//   - Redirecting to a random entry and to the word of the day, and serving its Atom feed.
 //   - Serving static assets such as CSS, JavaScript, and images.
 //
-// Note: Autocomplete/search functionality is implemented client-side in JavaScript.
+// Note: Autocomplete functionality is implemented client-side in JavaScript.
 package main
 
 import (
@@ -29,6 +32,39 @@ func main() {
 	mux.HandleFunc("GET /lema/{slug}", server.IndexAndEntryHandler)
 	mux.HandleFunc("GET /lletra/{letter}", server.LetterHandler)
 	mux.HandleFunc("GET /camp-semantic/{slug}", server.SemanticFieldHandler)
+	mux.HandleFunc("GET /glossari/lletra/{letter}", server.GlossaryLetterHandler)
+	mux.HandleFunc("GET /glossari/{term...}", server.GlossaryTermHandler)
+	mux.HandleFunc("GET /modismes", server.IdiomsHandler)
+	mux.HandleFunc("GET /modismes/lletra/{letter}", server.IdiomLetterHandler)
+	mux.HandleFunc("GET /modismes/lemes", server.IdiomsByEntryHandler)
+	mux.HandleFunc("GET /modismes.json", server.IdiomsDataHandler)
+	mux.HandleFunc("GET /sinonim/{word}", server.SynonymHandler)
+	mux.HandleFunc("GET /cerca", server.TextSearchHandler)
+	mux.HandleFunc("GET /atzar", server.RandomEntryHandler)
+	mux.HandleFunc("GET /lema-del-dia", server.DailyEntryHandler)
+	mux.HandleFunc("GET /lema-del-dia.xml", server.DailyEntryFeedHandler)
+	mux.HandleFunc("GET /api/suggeriments", server.SuggestionsAPIHandler)
+	mux.HandleFunc("GET /opensearch.xml", server.OpenSearchHandler)
+
+	// Unversioned paths of the first API endpoints, kept for existing clients
+	mux.HandleFunc("GET /api/cerca", server.WithCORS(server.SearchAPIHandler))
+	mux.HandleFunc("GET /api/cerca/text", server.WithCORS(server.TextSearchAPIHandler))
+	mux.HandleFunc("GET /api/lema/{slug}", server.WithCORS(server.EntryAPIHandler))
+	mux.HandleFunc("GET /api/sinonim/{word}", server.WithCORS(server.SynonymAPIHandler))
+
+	mux.HandleFunc("GET /api/v1/lema/{slug}", server.WithCORS(server.EntryAPIHandler))
+	mux.HandleFunc("GET /api/v1/lletres", server.WithCORS(server.LettersAPIHandler))
+	mux.HandleFunc("GET /api/v1/lletra/{letter}", server.WithCORS(server.LetterAPIHandler))
+	mux.HandleFunc("GET /api/v1/camps-semantics", server.WithCORS(server.SemanticFieldsAPIHandler))
+	mux.HandleFunc("GET /api/v1/camp-semantic/{slug}", server.WithCORS(server.SemanticFieldAPIHandler))
+	mux.HandleFunc("GET /api/v1/glossari", server.WithCORS(server.GlossaryAPIHandler))
+	mux.HandleFunc("GET /api/v1/glossari/{letter}", server.WithCORS(server.GlossaryLetterAPIHandler))
+	mux.HandleFunc("GET /api/v1/cerca", server.WithCORS(server.SearchAPIHandler))
+	mux.HandleFunc("GET /api/v1/cerca/text", server.WithCORS(server.TextSearchAPIHandler))
+	mux.HandleFunc("GET /api/v1/sinonim/{word}", server.WithCORS(server.SynonymAPIHandler))
+	mux.HandleFunc("GET /api/v1/lema-del-dia", server.WithCORS(server.DailyScheduleAPIHandler))
+	mux.HandleFunc("GET /api/v1/openapi.json", server.WithCORS(server.OpenAPIHandler))
+
 	for _, page := range core.StaticPages {
 		mux.HandleFunc("GET /"+page.Path, server.BasicPageHandler(page.Path, page.Title))
 	}
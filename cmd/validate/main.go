@@ -0,0 +1,239 @@
+// Package main implements the data validation command for DIRELEX.
+//
+// The command loads the data file exported from the CMS and checks that:
+//   - Entry slugs are unique.
+//   - Normalized titles are the lowercased, accent-stripped form of the titles.
+//   - Entries are sorted in Catalan collation order, as letter pages and
+//     previous/next navigation rely on the export order.
+//   - Entry, glossary and semantic field contents are well-formed HTML.
+//   - Glossary terms are listed under the letter their id starts with.
+//   - Glossary term ids can be the paths of their pages (see core.CheckGlossaryTermID).
+//   - Internal links point to existing pages.
+//
+// It prints a report of the problems found and exits with a non-zero status if there are any.
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/softcatala/direlex/internal/core"
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// check is a validation check over the loaded data, returning the problems found.
+type check struct {
+	name string
+	run  func() []string
+}
+
+func main() {
+	path := "data/data.json.gz"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+
+	err := core.LoadDataFromFile(path)
+	if err != nil {
+		log.Fatalf("Failed to load data: %v", err)
+	}
+
+	checks := []check{
+		{"Duplicated slugs", checkDuplicatedSlugs},
+		{"Normalized titles", checkNormalizedTitles},
+		{"Collation order", checkCollationOrder},
+		{"Malformed HTML", checkHTML},
+		{"Glossary letters", checkGlossaryLetters},
+		{"Glossary ids", checkGlossaryIDs},
+		{"Dead links", checkLinks},
+	}
+
+	total := 0
+	for _, c := range checks {
+		problems := c.run()
+		total += len(problems)
+		if len(problems) == 0 {
+			fmt.Printf("✓ %s\n", c.name)
+			continue
+		}
+		fmt.Printf("✗ %s: %d problems\n", c.name, len(problems))
+		for _, problem := range problems {
+			fmt.Printf("    %s\n", problem)
+		}
+	}
+
+	if total > 0 {
+		log.Fatalf("Validation failed: %d problems found in %s", total, path)
+	}
+	log.Printf("Validation passed: %d entries, %d glossary terms, %d semantic fields.", len(core.AllEntries), len(core.GlossaryTerms), len(core.SemanticFields))
+}
+
+// checkDuplicatedSlugs reports slugs used by more than one entry.
+func checkDuplicatedSlugs() []string {
+	var problems []string
+	seen := make(map[string]int, len(core.AllEntries))
+	for i, entry := range core.AllEntries {
+		if first, ok := seen[entry.Slug]; ok {
+			problems = append(problems, fmt.Sprintf("%q: entries %d and %d", entry.Slug, first, i))
+			continue
+		}
+		seen[entry.Slug] = i
+	}
+	return problems
+}
+
+// checkNormalizedTitles reports entries whose normalized title in the data file
+// does not match their title. They are fixed at load time, so the exported values
+// are taken from core.FixedNormalizedTitles.
+func checkNormalizedTitles() []string {
+	var problems []string
+	for _, entry := range core.AllEntries {
+		exported, ok := core.FixedNormalizedTitles[entry.Slug]
+		if ok {
+			problems = append(problems, fmt.Sprintf("%q: title_normalized is %q, expected %q", entry.Slug, exported, entry.NormalizedTitle))
+		}
+	}
+	return problems
+}
+
+// checkCollationOrder reports entries sorted before the previous entry in the data
+// file. They are sorted at load time, so they are taken from core.UnsortedEntries.
+func checkCollationOrder() []string {
+	var problems []string
+	for _, slug := range core.UnsortedEntries {
+		problems = append(problems, fmt.Sprintf("%q is not in Catalan collation order", slug))
+	}
+	return problems
+}
+
+// checkHTML reports contents that are not well-formed HTML fragments.
+func checkHTML() []string {
+	var problems []string
+	for _, entry := range core.AllEntries {
+		for _, err := range validateHTML(entry.Content) {
+			problems = append(problems, fmt.Sprintf("/lema/%s: %s", entry.Slug, err))
+		}
+	}
+	for _, letter := range slices.Sorted(maps.Keys(core.Glossary)) {
+		for _, err := range validateHTML(string(core.Glossary[letter])) {
+			problems = append(problems, fmt.Sprintf("/glossari (%s): %s", letter, err))
+		}
+	}
+	for _, field := range core.SemanticFields {
+		for _, err := range validateHTML(field.Body) {
+			problems = append(problems, fmt.Sprintf("/camp-semantic/%s: %s", field.Path, err))
+		}
+	}
+	return problems
+}
+
+// checkGlossaryLetters reports glossary terms listed under a letter other than
+// the first letter of their id.
+func checkGlossaryLetters() []string {
+	var problems []string
+	for _, term := range core.GlossaryTerms {
+		letter := strings.ToUpper(catalan.FirstLetter(term.ID))
+		if letter != term.Letter {
+			problems = append(problems, fmt.Sprintf("%q is listed under %s, expected %s", term.ID, term.Letter, letter))
+		}
+	}
+	return problems
+}
+
+// checkGlossaryIDs reports glossary term ids that cannot be the paths of their pages.
+func checkGlossaryIDs() []string {
+	var problems []string
+	seen := make(map[string]bool, len(core.GlossaryTerms))
+	for _, term := range core.GlossaryTerms {
+		if seen[term.ID] {
+			continue
+		}
+		seen[term.ID] = true
+		err := core.CheckGlossaryTermID(term.ID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q (%s): %v", term.ID, term.Letter, err))
+		}
+	}
+	return problems
+}
+
+// checkLinks reports links to entries or semantic fields that do not exist.
+func checkLinks() []string {
+	var problems []string
+	for _, link := range core.GetBrokenLinks() {
+		problems = append(problems, fmt.Sprintf("%s: %s", link.SourcePath(), link.Href))
+	}
+	return problems
+}
+
+// validateHTML checks that an HTML fragment is well-formed: every tag is closed in
+// order, except for void elements, and no "<" is left unescaped.
+func validateHTML(s string) []string {
+	var problems []string
+	var stack []string
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			problems = append(problems, fmt.Sprintf("unterminated tag at offset %d", i))
+			break
+		}
+		tag := s[i+1 : i+end]
+		if strings.ContainsRune(tag, '<') || tag == "" || unicode.IsSpace(rune(tag[0])) {
+			problems = append(problems, fmt.Sprintf("unescaped \"<\" at offset %d", i))
+			i++
+			continue
+		}
+		i += end + 1
+
+		if strings.HasPrefix(tag, "!") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(tag, "/"))
+		if len(fields) == 0 || strings.Trim(fields[0], "/") == "" {
+			problems = append(problems, fmt.Sprintf("tag without name <%s> at offset %d", tag, i-end-1))
+			continue
+		}
+		name := strings.ToLower(strings.TrimRight(fields[0], "/"))
+		switch {
+		case strings.HasPrefix(tag, "/"):
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				open := "none"
+				if len(stack) > 0 {
+					open = "<" + stack[len(stack)-1] + ">"
+				}
+				problems = append(problems, fmt.Sprintf("unexpected </%s> at offset %d (open element: %s)", name, i-end-1, open))
+				if j := slices.Index(stack, name); j >= 0 {
+					stack = stack[:j]
+				}
+				continue
+			}
+			stack = stack[:len(stack)-1]
+		case isVoidElement(name) || strings.HasSuffix(tag, "/"):
+		default:
+			stack = append(stack, name)
+		}
+	}
+	for _, name := range stack {
+		problems = append(problems, fmt.Sprintf("unclosed <%s>", name))
+	}
+	return problems
+}
+
+// isVoidElement reports whether the HTML element has no closing tag.
+func isVoidElement(name string) bool {
+	switch name {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	default:
+		return false
+	}
+}
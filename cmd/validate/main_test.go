@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+func TestValidateHTML(t *testing.T) {
+	tests := []struct {
+		html string
+		want []string // Substrings of the problems found, in order
+	}{
+		{"", nil},
+		{"<p>text <strong>bold</strong><br></p>", nil},
+		{"<p>a<br/>b</p><!-- comment -->", nil},
+		{`<a href="/lema/aire">aire</a>`, nil},
+		{"<p>text", []string{"unclosed <p>"}},
+		{"<p><em>text</p>", []string{"unexpected </p> at offset 11 (open element: <em>)"}},
+		{"</p>", []string{"unexpected </p> at offset 0 (open element: none)"}},
+		{"<p>a < b</p>", []string{`unescaped "<" at offset 5`}},
+		{"<p>text</p", []string{"unterminated tag at offset 7", "unclosed <p>"}},
+		{"<p>a<>b</p>", []string{`unescaped "<" at offset 4`}},
+		{"<p>a</>b</p>", []string{"tag without name </> at offset 4"}},
+		{"<p>a</ >b</p>", []string{"tag without name </ > at offset 4"}},
+		{"<p>a<//>b</p>", []string{"tag without name <//> at offset 4"}},
+	}
+	for _, tt := range tests {
+		got := validateHTML(tt.html)
+		if len(got) != len(tt.want) {
+			t.Errorf("validateHTML(%q) = %q; want %d problems", tt.html, got, len(tt.want))
+			continue
+		}
+		for i, problem := range got {
+			if !strings.Contains(problem, tt.want[i]) {
+				t.Errorf("validateHTML(%q) problem %d = %q; want it to contain %q", tt.html, i, problem, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCheckGlossaryIDs(t *testing.T) {
+	saved := core.GlossaryTerms
+	t.Cleanup(func() { core.GlossaryTerms = saved })
+	core.GlossaryTerms = []core.GlossaryTerm{
+		{ID: "abatut", Letter: "A"},
+		{ID: "abreviació/abreviatura", Letter: "A"},
+		{ID: "../index", Letter: "I"},
+		{ID: "../index", Letter: "I"}, // Reported once
+		{ID: "lletra/a", Letter: "L"},
+	}
+
+	want := []string{
+		`"../index" (I): ".." path segment`,
+		`"lletra/a" (L): id starting with "lletra/"`,
+	}
+	if got := checkGlossaryIDs(); !slices.Equal(got, want) {
+		t.Errorf("checkGlossaryIDs() = %q; want %q", got, want)
+	}
+}
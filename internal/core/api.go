@@ -0,0 +1,224 @@
+package core
+
+import (
+	_ "embed"
+	"html/template"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// APIPath is the path of the versioned JSON API. The server serves it and the
+// generator writes the same responses as JSON files.
+const APIPath = "/api/v1"
+
+// OpenAPIDocument is the OpenAPI description of the JSON API.
+//
+//go:embed openapi.json
+var OpenAPIDocument []byte
+
+// LetterSummary represents a letter of the dictionary or the glossary, in the JSON API.
+type LetterSummary struct {
+	Letter string `json:"letter"`
+	Count  int    `json:"count"` // Number of entries or glossary terms
+	URL    string `json:"url"`
+	APIURL string `json:"api_url"`
+}
+
+// LettersData is the JSON API response listing the letters of the dictionary or the glossary.
+type LettersData struct {
+	Letters []LetterSummary `json:"letters"`
+}
+
+// EntrySummary represents an entry in lists of the JSON API.
+type EntrySummary struct {
+	Slug         string        `json:"slug"`
+	DisplayTitle template.HTML `json:"title"`
+	URL          string        `json:"url"`
+	APIURL       string        `json:"api_url"`
+}
+
+// LetterData is the JSON API response listing the entries starting with a letter.
+type LetterData struct {
+	Letter     string         `json:"letter"`
+	PrevLetter string         `json:"prev_letter,omitempty"`
+	NextLetter string         `json:"next_letter,omitempty"`
+	Entries    []EntrySummary `json:"entries"`
+}
+
+// SemanticFieldSummary represents a semantic field in lists of the JSON API.
+type SemanticFieldSummary struct {
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	APIURL string `json:"api_url"`
+}
+
+// SemanticFieldsData is the JSON API response listing the semantic fields.
+type SemanticFieldsData struct {
+	SemanticFields []SemanticFieldSummary `json:"semantic_fields"`
+}
+
+// SemanticFieldData is the JSON API response of a semantic field.
+type SemanticFieldData struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	HTML  string `json:"html"`
+}
+
+// GlossaryLetterData is the JSON API response listing the glossary terms of a letter.
+type GlossaryLetterData struct {
+	Letter     string             `json:"letter"`
+	PrevLetter string             `json:"prev_letter,omitempty"`
+	NextLetter string             `json:"next_letter,omitempty"`
+	Terms      []GlossaryTermData `json:"terms"`
+}
+
+// GlossaryTermData represents a glossary term (see GlossaryTerm), in the JSON API.
+type GlossaryTermData struct {
+	ID       string               `json:"id"`
+	Term     string               `json:"term"`
+	Synonyms string               `json:"synonyms,omitempty"`
+	URL      string               `json:"url"`
+	HTML     template.HTML        `json:"html"`
+	Targets  []GlossaryTargetData `json:"targets"`
+}
+
+// GlossaryTargetData represents an entry referenced by a glossary term (see GlossaryTarget),
+// in the JSON API.
+type GlossaryTargetData struct {
+	Slug         string `json:"slug"`
+	Title        string `json:"title"`
+	Reference    string `json:"reference,omitempty"`
+	PartOfSpeech string `json:"part_of_speech,omitempty"`
+	Sense        string `json:"sense,omitempty"`
+	URL          string `json:"url"`
+}
+
+// EntryAPIPath returns the JSON API path of the entry with the given slug.
+func EntryAPIPath(slug string) string {
+	return APIPath + "/lema/" + url.PathEscape(slug)
+}
+
+// GlossaryTermPath returns the URL path of the glossary term page with the given id.
+// Slashes in ids are kept, as in the paths of the generated pages.
+func GlossaryTermPath(id string) string {
+	return "/glossari/" + strings.ReplaceAll(url.PathEscape(id), "%2F", "/")
+}
+
+// GetLettersData returns the letters of the dictionary with their number of entries.
+func GetLettersData() LettersData {
+	data := LettersData{Letters: []LetterSummary{}}
+	for _, letter := range DictionaryLetters {
+		data.Letters = append(data.Letters, LetterSummary{
+			Letter: letter,
+			Count:  len(GetEntriesByFirstLetter(letter)),
+			URL:    "/lletra/" + url.PathEscape(letter),
+			APIURL: APIPath + "/lletra/" + url.PathEscape(letter),
+		})
+	}
+	return data
+}
+
+// GetLetterData returns the entries starting with the given lowercase letter.
+// It reports false if there are none.
+func GetLetterData(letter string) (LetterData, bool) {
+	entries := GetEntriesByFirstLetter(letter)
+	if len(entries) == 0 {
+		return LetterData{}, false
+	}
+
+	prevLetter, nextLetter := GetNavigationLetters(letter)
+	data := LetterData{Letter: letter, PrevLetter: prevLetter, NextLetter: nextLetter}
+	for _, entry := range entries {
+		data.Entries = append(data.Entries, EntrySummary{
+			Slug:         entry.Slug,
+			DisplayTitle: entry.DisplayTitle,
+			URL:          EntryPath(entry.Slug),
+			APIURL:       EntryAPIPath(entry.Slug),
+		})
+	}
+	return data, true
+}
+
+// GetSemanticFieldsData returns the semantic fields, in data file order.
+func GetSemanticFieldsData() SemanticFieldsData {
+	data := SemanticFieldsData{SemanticFields: []SemanticFieldSummary{}}
+	for _, field := range SemanticFields {
+		data.SemanticFields = append(data.SemanticFields, SemanticFieldSummary{
+			Slug:   field.Path,
+			Title:  field.Title,
+			URL:    "/camp-semantic/" + url.PathEscape(field.Path),
+			APIURL: APIPath + "/camp-semantic/" + url.PathEscape(field.Path),
+		})
+	}
+	return data
+}
+
+// GetSemanticFieldData returns the semantic field with the given slug.
+func GetSemanticFieldData(slug string) (SemanticFieldData, bool) {
+	for _, field := range SemanticFields {
+		if field.Path == slug {
+			return SemanticFieldData{
+				Slug:  field.Path,
+				Title: field.Title,
+				URL:   "/camp-semantic/" + url.PathEscape(field.Path),
+				HTML:  field.Body,
+			}, true
+		}
+	}
+	return SemanticFieldData{}, false
+}
+
+// GetGlossaryLettersData returns the letters of the glossary with their number of terms.
+// Letters are lowercase in URLs, as in the glossary letter pages.
+func GetGlossaryLettersData() LettersData {
+	data := LettersData{Letters: []LetterSummary{}}
+	for _, letter := range slices.Sorted(maps.Keys(Glossary)) {
+		lower := strings.ToLower(letter)
+		data.Letters = append(data.Letters, LetterSummary{
+			Letter: letter,
+			Count:  len(GetGlossaryTermsByLetter(letter)),
+			URL:    "/glossari/lletra/" + url.PathEscape(lower),
+			APIURL: APIPath + "/glossari/" + url.PathEscape(lower),
+		})
+	}
+	return data
+}
+
+// GetGlossaryLetterData returns the glossary terms listed under the given letter,
+// in any case. It reports false if there are none.
+func GetGlossaryLetterData(letter string) (GlossaryLetterData, bool) {
+	letter = strings.ToUpper(letter)
+	terms := GetGlossaryTermsByLetter(letter)
+	if len(terms) == 0 {
+		return GlossaryLetterData{}, false
+	}
+
+	prevLetter, nextLetter := GetGlossaryNavigationLetters(letter)
+	data := GlossaryLetterData{Letter: letter, PrevLetter: prevLetter, NextLetter: nextLetter}
+	for _, term := range terms {
+		termData := GlossaryTermData{
+			ID:       term.ID,
+			Term:     term.Term,
+			Synonyms: term.Synonyms,
+			URL:      GlossaryTermPath(term.ID),
+			HTML:     term.HTML,
+			Targets:  []GlossaryTargetData{},
+		}
+		for _, target := range term.Targets {
+			termData.Targets = append(termData.Targets, GlossaryTargetData{
+				Slug:         target.Slug,
+				Title:        target.Title,
+				Reference:    target.Reference,
+				PartOfSpeech: target.PartOfSpeech,
+				Sense:        target.Sense,
+				URL:          EntryPath(target.Slug),
+			})
+		}
+		data.Terms = append(data.Terms, termData)
+	}
+	return data, true
+}
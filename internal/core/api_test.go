@@ -0,0 +1,49 @@
+package core
+
+import "testing"
+
+func TestGetLetterData(t *testing.T) {
+	loadTestData(t)
+
+	data, ok := GetLetterData("a")
+	if !ok {
+		t.Fatal(`GetLetterData("a") not found`)
+	}
+	if data.PrevLetter != "" || data.NextLetter != "p" {
+		t.Errorf(`GetLetterData("a") prev, next = %q, %q; want "", "p"`, data.PrevLetter, data.NextLetter)
+	}
+	var slugs []string
+	for _, entry := range data.Entries {
+		slugs = append(slugs, entry.Slug)
+	}
+	if len(slugs) != 2 || slugs[0] != "abril" || slugs[1] != "aire" {
+		t.Errorf(`GetLetterData("a") entries = %v; want [abril aire]`, slugs)
+	}
+
+	for _, letter := range []string{"", "b", "z", "ab", "ç"} {
+		if _, ok := GetLetterData(letter); ok {
+			t.Errorf("GetLetterData(%q) found", letter)
+		}
+	}
+}
+
+func TestGetGlossaryLetterData(t *testing.T) {
+	loadTestData(t)
+
+	for _, letter := range []string{"a", "A"} {
+		data, ok := GetGlossaryLetterData(letter)
+		if !ok {
+			t.Errorf("GetGlossaryLetterData(%q) not found", letter)
+			continue
+		}
+		if data.Letter != "A" || data.NextLetter != "V" || len(data.Terms) != 2 {
+			t.Errorf("GetGlossaryLetterData(%q) = %s with %d terms, next %q; want A with 2 terms, next V", letter, data.Letter, len(data.Terms), data.NextLetter)
+		}
+	}
+
+	for _, letter := range []string{"", "b", "Z", "AB"} {
+		if _, ok := GetGlossaryLetterData(letter); ok {
+			t.Errorf("GetGlossaryLetterData(%q) found", letter)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package catalan provides the normalization and collation of Catalan text used
+// to search and sort the dictionary entries.
+package catalan
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// middleDotPattern matches the ela geminada written with a period or a similar
+// character instead of the middle dot, e.g. "col.legi" or "col•legi". The
+// precomposed "ŀl" is handled by canonicalReplacer.
+var middleDotPattern = regexp.MustCompile(`(?i)(l)[.•‧∙⋅](l)`)
+
+// canonicalReplacer replaces the typographic variants of apostrophes, hyphens
+// and the ela geminada with their canonical forms.
+var canonicalReplacer = strings.NewReplacer(
+	"’", "'", "‘", "'", "ʼ", "'", "´", "'", "`", "'",
+	"‐", "-", "‑", "-", "−", "-", "­", "",
+	"ŀl", "l·l", "Ŀl", "L·l", "ĿL", "L·L",
+)
+
+// accentReplacer removes the accents of the Catalan vowels. The cedilla is kept,
+// as "ç" is a letter of its own in the normalized form.
+var accentReplacer = strings.NewReplacer(
+	"à", "a", "è", "e", "é", "e", "í", "i", "ï", "i", "ò", "o", "ó", "o", "ú", "u", "ü", "u",
+)
+
+// Canonicalize returns a text with typographic apostrophes and hyphens replaced by
+// their ASCII forms, and the ela geminada written with a middle dot ("l·l").
+func Canonicalize(s string) string {
+	s = canonicalReplacer.Replace(s)
+	return middleDotPattern.ReplaceAllString(s, "$1·$2")
+}
+
+// Normalize returns the searchable form of a text: canonical, lowercase and without
+// the accents of the vowels. It is the form exported as title_normalized, so "ç" and
+// "l·l" are kept, e.g. "Pel·lícula" gives "pel·licula".
+func Normalize(s string) string {
+	return accentReplacer.Replace(strings.ToLower(Canonicalize(s)))
+}
+
+// Fold returns the normalized form of a text with "ç" replaced by "c".
+func Fold(s string) string {
+	return strings.ReplaceAll(Normalize(s), "ç", "c")
+}
+
+// Key returns the primary collation key of a text: its folded letters and digits,
+// ignoring the middle dot of "l·l", hyphens, apostrophes and other punctuation.
+// Words are separated by a single space, which sorts before any letter, so that
+// "baix | baixa" sorts before "baixar".
+func Key(s string) string {
+	return collationWords(Fold(s))
+}
+
+// secondaryKey returns the collation key of a text used to break ties between
+// texts with the same primary key: its lowercase letters, with their accents.
+func secondaryKey(s string) string {
+	return collationWords(strings.ToLower(Canonicalize(s)))
+}
+
+// collationWords returns the letters and digits of a text, with words separated
+// by a single space. Slugs and titles separate words with "_", "|" or "/".
+func collationWords(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r) || r == '|' || r == '_' || r == '/':
+			return ' '
+		default:
+			return -1
+		}
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Compare compares two texts in Catalan collation order, returning -1, 0 or 1.
+// Texts are compared by their letters first, so that accents, "ç", "l·l", hyphens
+// and apostrophes do not change the alphabetical order; then unaccented letters
+// sort before accented ones (e.g. "te" before "té"); and finally texts are
+// compared as written.
+func Compare(a, b string) int {
+	if c := strings.Compare(Key(a), Key(b)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(secondaryKey(a), secondaryKey(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// FirstLetter returns the lowercase letter a text is listed under: its first
+// letter, without accents and with "ç" as "c". It returns an empty string if the
+// text has no letters.
+func FirstLetter(s string) string {
+	for _, r := range Key(s) {
+		if unicode.IsLetter(r) {
+			return string(r)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package catalan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"Aire", "aire"},
+		{"Pel·lícula", "pel·licula"},
+		{"col.legi", "col·legi"},
+		{"col•legi", "col·legi"},
+		{"coŀlegi", "col·legi"},
+		{"COĿLEGI", "col·legi"},
+		{"Català", "catala"},
+		{"pingüí", "pingui"},
+		{"l’aire", "l'aire"},
+		{"nord‑oest", "nord-oest"},
+		{"ca­sa", "casa"}, // Soft hyphen
+		{"plaça", "plaça"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Plaça", "placa"},
+		{"col·legi", "collegi"},
+		{"baix | baixa", "baix baixa"},
+		{"l'aire", "laire"},
+		{"nord-oest", "nordoest"},
+		{"fer_se", "fer se"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each word sorts before the next one.
+	sorted := []string{
+		"a", "à", "abans", "baix | baixa", "baixar", "caç", "caça", "caçar", "cap",
+		"col·legi", "collir", "te", "té", "tea", "zero",
+	}
+	for i := range len(sorted) - 1 {
+		a, b := sorted[i], sorted[i+1]
+		if Compare(a, b) >= 0 || Compare(b, a) <= 0 {
+			t.Errorf("Compare(%q, %q) = %d; want %q first", a, b, Compare(a, b), a)
+		}
+	}
+	if c := Compare("aire", "aire"); c != 0 {
+		t.Errorf("Compare(aire, aire) = %d; want 0", c)
+	}
+
+	shuffled := slices.Clone(sorted)
+	slices.Reverse(shuffled)
+	slices.SortFunc(shuffled, Compare)
+	if !slices.Equal(shuffled, sorted) {
+		t.Errorf("sorted = %q; want %q", shuffled, sorted)
+	}
+}
+
+func TestFirstLetter(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Àvia", "a"},
+		{"çaçaia", "c"},
+		{"'l'aire", "l"},
+		{"-ada", "a"},
+		{"123", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := FirstLetter(tt.in); got != tt.want {
+			t.Errorf("FirstLetter(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"cmp"
+	"encoding/xml"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Days of the word of the day schedule and feed, around the current date.
+const (
+	dailyFeedDays     = 30  // Days listed in the feed, up to the current date
+	dailyScheduleDays = 365 // Days listed in the schedule after the current date
+)
+
+// dateLayout is the layout of the dates of the word of the day.
+const dateLayout = "2006-01-02"
+
+// DailyEntry represents the word of the day of a date, in the JSON API.
+type DailyEntry struct {
+	Date   string `json:"date"` // UTC date, e.g. "2025-04-23"
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	APIURL string `json:"api_url"`
+}
+
+// DailyScheduleData is the JSON API response listing the words of the day.
+type DailyScheduleData struct {
+	Days []DailyEntry `json:"days"`
+}
+
+// atomFeed is an Atom feed (RFC 4287).
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr"`
+	Base    string      `xml:"xml:base,attr,omitempty"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// GetRandomEntry returns an entry chosen at random.
+func GetRandomEntry() Entry {
+	return AllEntries[rand.IntN(len(AllEntries))]
+}
+
+// GetDailyEntry returns the word of the day of the given date, in UTC (see dailyOrders).
+func GetDailyEntry(date time.Time) Entry {
+	return dailyOrders{}.entry(date)
+}
+
+// dailyOrders holds the order of the words of the day of each year, so that
+// the entries are ordered once per year when listing several days.
+type dailyOrders map[int][]int
+
+// entry returns the word of the day of the given date, in UTC. Each day of the year
+// takes the next entry of the order of the year, so that no entry is repeated within
+// a year.
+func (orders dailyOrders) entry(date time.Time) Entry {
+	date = date.UTC()
+	order, ok := orders[date.Year()]
+	if !ok {
+		order = dailyOrder(date.Year())
+		orders[date.Year()] = order
+	}
+	return AllEntries[order[(date.YearDay()-1)%len(order)]]
+}
+
+// dailyOrder returns the indexes of the entries ordered by a hash of the year and their
+// slug, rather than by a shuffle of all the entries, so that adding or removing an entry
+// only changes the days of the year after its position in the order.
+func dailyOrder(year int) []int {
+	hashes := make([]uint64, len(AllEntries))
+	for i, entry := range AllEntries {
+		h := fnv.New64a()
+		h.Write([]byte(strconv.Itoa(year) + "/" + entry.Slug))
+		hashes[i] = h.Sum64()
+	}
+	order := make([]int, len(AllEntries))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Or(cmp.Compare(hashes[a], hashes[b]), cmp.Compare(AllEntries[a].Slug, AllEntries[b].Slug))
+	})
+	return order
+}
+
+// GetDailySchedule returns the words of the day from dailyFeedDays days before the
+// given date to dailyScheduleDays days after it.
+func GetDailySchedule(today time.Time) DailyScheduleData {
+	data := DailyScheduleData{Days: []DailyEntry{}}
+	orders := dailyOrders{}
+	for day := -dailyFeedDays + 1; day <= dailyScheduleDays; day++ {
+		date := today.UTC().AddDate(0, 0, day)
+		entry := orders.entry(date)
+		data.Days = append(data.Days, DailyEntry{
+			Date:   date.Format(dateLayout),
+			Slug:   entry.Slug,
+			Title:  plainText(entry.DisplayTitle),
+			URL:    EntryPath(entry.Slug),
+			APIURL: EntryAPIPath(entry.Slug),
+		})
+	}
+	return data
+}
+
+// DailyEntryFeed returns the Atom feed of the words of the day of the last
+// dailyFeedDays days up to the given date, newest first, with the full entries.
+// Links are relative to siteURL, as feed readers need absolute URLs.
+func DailyEntryFeed(siteURL string, today time.Time) ([]byte, error) {
+	today = today.UTC()
+	feed := atomFeed{
+		Lang:    "ca",
+		Base:    siteURL + "/",
+		ID:      siteURL + "/lema-del-dia.xml",
+		Title:   "DIRELEX: lema del dia",
+		Updated: today.Format(dateLayout) + "T00:00:00Z",
+		Author:  atomAuthor{Name: "DIRELEX"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: siteURL + "/lema-del-dia.xml"},
+			{Rel: "alternate", Type: "text/html", Href: siteURL + "/"},
+		},
+	}
+	orders := dailyOrders{}
+	for day := 0; day < dailyFeedDays; day++ {
+		date := today.AddDate(0, 0, -day).Format(dateLayout)
+		entry := orders.entry(today.AddDate(0, 0, -day))
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      siteURL + "/lema-del-dia.xml#" + date,
+			Title:   plainText(entry.DisplayTitle),
+			Updated: date + "T00:00:00Z",
+			Link:    atomLink{Rel: "alternate", Type: "text/html", Href: siteURL + EntryPath(entry.Slug)},
+			Content: atomContent{Type: "html", Body: RenderEntry(entry)},
+		})
+	}
+
+	content, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// withNumberedEntries replaces the entries with n entries titled "mot 1" to "mot n".
+func withNumberedEntries(t *testing.T, n int) {
+	t.Helper()
+	titles := make([]string, n)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("mot %d", i+1)
+	}
+	withEntries(t, titles...)
+}
+
+func TestGetDailyEntryDeterministic(t *testing.T) {
+	withNumberedEntries(t, 500)
+
+	date := time.Date(2025, time.April, 23, 23, 30, 0, 0, time.FixedZone("", -2*60*60))
+	want := GetDailyEntry(date).Slug
+	for range 3 {
+		if got := GetDailyEntry(date).Slug; got != want {
+			t.Fatalf("GetDailyEntry(%v) = %q; want %q", date, got, want)
+		}
+	}
+	// The date is taken in UTC.
+	if got := GetDailyEntry(date.UTC()).Slug; got != want {
+		t.Errorf("GetDailyEntry(%v) = %q; want %q", date.UTC(), got, want)
+	}
+	// The schedule agrees with GetDailyEntry.
+	for _, day := range GetDailySchedule(date).Days {
+		d, _ := time.Parse(dateLayout, day.Date)
+		if got := GetDailyEntry(d).Slug; got != day.Slug {
+			t.Errorf("schedule of %s = %q; GetDailyEntry = %q", day.Date, day.Slug, got)
+		}
+	}
+}
+
+func TestGetDailyEntryNoRepeat(t *testing.T) {
+	withNumberedEntries(t, 500)
+
+	for _, year := range []int{2024, 2025} { // A leap year and a common year
+		seen := make(map[string]string)
+		for date := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); date.Year() == year; date = date.AddDate(0, 0, 1) {
+			slug := GetDailyEntry(date).Slug
+			if previous, ok := seen[slug]; ok {
+				t.Fatalf("%q is the word of the day of %s and %s", slug, previous, date.Format(dateLayout))
+			}
+			seen[slug] = date.Format(dateLayout)
+		}
+	}
+}
+
+func TestGetDailyEntryStable(t *testing.T) {
+	withNumberedEntries(t, 500)
+	schedule := make(map[time.Time]string)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for date := start; date.Year() == 2025; date = date.AddDate(0, 0, 1) {
+		schedule[date] = GetDailyEntry(date).Slug
+	}
+
+	// Adding an entry only changes the days after its position in the order of the year.
+	withNumberedEntries(t, 501)
+	added := "mot_501"
+	changed := false
+	for date := start; date.Year() == 2025; date = date.AddDate(0, 0, 1) {
+		slug := GetDailyEntry(date).Slug
+		if slug == added {
+			changed = true
+		}
+		if !changed && slug != schedule[date] {
+			t.Fatalf("word of the day of %s changed from %q to %q", date.Format(dateLayout), schedule[date], slug)
+		}
+	}
+}
@@ -8,10 +8,24 @@ import (
 // AllEntries contains all dictionary entries loaded from the data file.
 var AllEntries []Entry
 
+// FixedNormalizedTitles maps the slugs of the entries whose normalized title was
+// missing or wrong in the data file to the exported value.
+// It is built in LoadDataFromFile, which derives the right value from the title.
+var FixedNormalizedTitles map[string]string
+
+// UnsortedEntries contains the slugs of the entries sorted before the previous entry
+// in the data file. If there are any, LoadDataFromFile sorts AllEntries in Catalan
+// collation order.
+var UnsortedEntries []string
+
 // entryIndexBySlug maps an entry slug to its index in AllEntries.
 // It is built in LoadDataFromFile and treated as read-only afterwards.
 var entryIndexBySlug map[string]int
 
+// backlinksBySlug maps an entry slug to the pages linking to it.
+// It is built in LoadDataFromFile from Links.
+var backlinksBySlug map[string]Backlinks
+
 // DictionaryLetters contains the alphabet lowercase letters used at the start of a word.
 // It is populated dynamically from the entries.
 var DictionaryLetters []string
@@ -20,6 +34,33 @@ var DictionaryLetters []string
 // It maps uppercase letters to HTML content for that letter's content.
 var Glossary map[string]template.HTML
 
+// Links contains all links to dictionary pages found in entries, glossary and semantic fields.
+// It is built in LoadDataFromFile and used to detect broken links.
+var Links []Link
+
+// GlossaryTerms contains the glossary parsed into terms, sorted by letter.
+var GlossaryTerms []GlossaryTerm
+
+// glossaryTermIndexesByID maps a glossary term id to its indexes in GlossaryTerms.
+// Ids are not unique in the data (homonyms share the same id).
+var glossaryTermIndexesByID map[string][]int
+
+// Idioms contains the expressions of the "Modismes i fraseologia" subsections of
+// all entries, in dictionary order.
+var Idioms []Idiom
+
+// synonymSourcesByWord maps each normalized word listed as a synonym or related word
+// to the senses listing it. It is built in LoadDataFromFile.
+var synonymSourcesByWord map[string][]SynonymSource
+
+// suffixIndex is the index of titles and synonyms by their reversed normalized form,
+// used by SearchSuffix. It is built in LoadDataFromFile.
+var suffixIndex []suffixIndexItem
+
+// fullTextIndex is the inverted index of the entry contents used by SearchText.
+// It is built in LoadDataFromFile.
+var fullTextIndex textIndex
+
 // SemanticFields contains all semantic field pages loaded from the data file.
 var SemanticFields []SemanticField
 
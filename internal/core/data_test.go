@@ -0,0 +1,39 @@
+package core
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadTestData loads the data of testdata/data.json, a few entries with repeated sense
+// numbers, relations, idioms and glossary terms, as LoadDataFromFile loads the data file.
+func loadTestData(t *testing.T) {
+	t.Helper()
+	content, err := os.ReadFile("testdata/data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "data.json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := gzip.NewWriter(file)
+	_, err = w.Write(content)
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LoadDataFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// ExportTable is a table of the bulk export of the data (see cmd/export). The first
+// column is a key that is unique in the table and only depends on the data, built
+// from the key of the parent row and the position in it (e.g. "aire#1/2" for the
+// second synonym of the sense "1" of "aire").
+type ExportTable struct {
+	Name    string
+	Columns []string
+
+	// Rows hold strings, ints and, for lists, []string values.
+	Rows [][]any
+}
+
+// GetExportTables returns the tables of the bulk export: entries, senses, synonyms,
+// relations, idioms and glossary terms. HTML is converted to plain text, and the
+// raw HTML of entries and glossary terms is kept in a column of its own.
+func GetExportTables() []ExportTable {
+	return []ExportTable{
+		exportEntries(),
+		exportSenses(),
+		exportSynonyms(),
+		exportRelations(),
+		exportIdioms(),
+		exportGlossaryTerms(),
+	}
+}
+
+// senseKey returns the key of a sense in the export: the entry slug and the anchor
+// of the sense in the entry page, or only the slug outside any sense.
+func senseKey(slug, anchor string) string {
+	if anchor == "" {
+		return slug
+	}
+	return slug + "#" + anchor
+}
+
+// senseKeys returns the keys of the senses of an entry. Repeated sense numbers in
+// the data (e.g. two senses "5") get a suffix with their position (e.g. "por#5~2").
+func senseKeys(entry Entry) []string {
+	keys := make([]string, len(entry.Senses))
+	seen := make(map[string]int)
+	for i, sense := range entry.Senses {
+		key := senseKey(entry.Slug, sense.Anchor())
+		seen[key]++
+		if seen[key] > 1 {
+			key += fmt.Sprintf("~%d", seen[key])
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+func exportEntries() ExportTable {
+	table := ExportTable{
+		Name:    "entries",
+		Columns: []string{"id", "position", "title", "normalized_title", "letter", "url", "text", "html"},
+	}
+	for i, entry := range AllEntries {
+		title := plainText(entry.DisplayTitle)
+		table.Rows = append(table.Rows, []any{
+			entry.Slug,
+			i + 1,
+			title,
+			entry.NormalizedTitle,
+			catalan.FirstLetter(entry.NormalizedTitle),
+			EntryPath(entry.Slug),
+			plainText(entry.Content),
+			entry.Content,
+		})
+	}
+	return table
+}
+
+func exportSenses() ExportTable {
+	table := ExportTable{
+		Name: "senses",
+		Columns: []string{
+			"id", "entry_id", "position", "block", "part_of_speech", "form", "number",
+			"registers", "gloss", "header", "text",
+		},
+	}
+	for _, entry := range AllEntries {
+		keys := senseKeys(entry)
+		for i, sense := range entry.Senses {
+			var paragraphs []string
+			for _, subsection := range sense.Subsections {
+				for _, p := range subsection.Paragraphs {
+					paragraphs = append(paragraphs, plainText(p))
+				}
+			}
+			table.Rows = append(table.Rows, []any{
+				keys[i],
+				entry.Slug,
+				i + 1,
+				sense.Block + 1,
+				sense.PartOfSpeech,
+				sense.Form,
+				sense.Number,
+				nonNil(sense.Registers),
+				sense.Gloss,
+				plainText(sense.HeaderHTML),
+				strings.Join(paragraphs, " "),
+			})
+		}
+	}
+	return table
+}
+
+func exportSynonyms() ExportTable {
+	table := ExportTable{
+		Name:    "synonyms",
+		Columns: []string{"id", "sense_id", "entry_id", "position", "word", "note", "target_entry_id"},
+	}
+	entrySlugsByWord := buildEntrySlugsByWord()
+	for _, entry := range AllEntries {
+		keys := senseKeys(entry)
+		for j, sense := range entry.Senses {
+			key := keys[j]
+			for i, word := range sense.Synonyms {
+				target := parseRelationTarget(strings.TrimRight(word, "!?"))
+				table.Rows = append(table.Rows, []any{
+					fmt.Sprintf("%s/%d", key, i+1),
+					key,
+					entry.Slug,
+					i + 1,
+					target.Word,
+					target.Note,
+					entrySlugsByWord[target.Word],
+				})
+			}
+		}
+	}
+	return table
+}
+
+func exportRelations() ExportTable {
+	table := ExportTable{
+		Name: "relations",
+		Columns: []string{
+			"id", "sense_id", "entry_id", "kind", "position", "group", "word", "note", "target_entry_id",
+		},
+	}
+	for _, entry := range AllEntries {
+		// Targets are numbered by sense and kind, as a sense may have several relations of a kind.
+		keys := senseKeys(entry)
+		positions := make(map[string]int)
+		for _, relation := range entry.Relations {
+			key := entry.Slug
+			if relation.senseIndex >= 0 {
+				key = keys[relation.senseIndex]
+			}
+			for _, target := range relation.Targets {
+				positions[key+"/"+relation.Kind]++
+				position := positions[key+"/"+relation.Kind]
+				table.Rows = append(table.Rows, []any{
+					fmt.Sprintf("%s/%s/%d", key, relation.Kind, position),
+					key,
+					entry.Slug,
+					relation.Kind,
+					position,
+					target.Group + 1,
+					target.Word,
+					target.Note,
+					target.Slug,
+				})
+			}
+		}
+	}
+	return table
+}
+
+func exportIdioms() ExportTable {
+	table := ExportTable{
+		Name: "idioms",
+		Columns: []string{
+			"id", "sense_id", "entry_id", "position", "expression", "forms", "gloss", "examples", "letter",
+		},
+	}
+	positions := make(map[string]int)
+	for _, idiom := range Idioms {
+		// Idioms are filed under the "d" subsection of a sense, and belong to the sense.
+		key := senseKeys(AllEntries[entryIndexBySlug[idiom.Slug]])[idiom.senseIndex]
+		positions[key]++
+		table.Rows = append(table.Rows, []any{
+			fmt.Sprintf("%s/%d", key, positions[key]),
+			key,
+			idiom.Slug,
+			positions[key],
+			idiom.Expression,
+			nonNil(idiom.Forms),
+			idiom.Gloss,
+			nonNil(idiom.Examples),
+			idiom.Letter,
+		})
+	}
+	return table
+}
+
+func exportGlossaryTerms() ExportTable {
+	table := ExportTable{
+		Name: "glossary_terms",
+		Columns: []string{
+			"id", "term_id", "letter", "term", "synonyms", "target_entry_ids", "target_references", "text", "html",
+		},
+	}
+	positions := make(map[string]int)
+	for _, term := range GlossaryTerms {
+		positions[term.ID]++
+		targets, references := []string{}, []string{}
+		for _, target := range term.Targets {
+			targets = append(targets, target.Slug)
+			references = append(references, target.Reference)
+		}
+		table.Rows = append(table.Rows, []any{
+			fmt.Sprintf("%s~%d", term.ID, positions[term.ID]),
+			term.ID,
+			term.Letter,
+			term.Term,
+			term.Synonyms,
+			targets,
+			references,
+			plainText(string(term.HTML)),
+			string(term.HTML),
+		})
+	}
+	return table
+}
+
+// nonNil returns the list, or an empty list if it is nil, so that lists are never
+// exported as null.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
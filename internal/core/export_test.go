@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExportTables(t *testing.T) {
+	loadTestData(t)
+
+	tables := make(map[string]ExportTable)
+	for _, table := range GetExportTables() {
+		tables[table.Name] = table
+	}
+
+	// Keys are unique, and rows have a value per column.
+	keys := make(map[string]map[string]bool)
+	for name, table := range tables {
+		keys[name] = make(map[string]bool)
+		for _, row := range table.Rows {
+			if len(row) != len(table.Columns) {
+				t.Errorf("%s row %v has %d values; want %d", name, row[0], len(row), len(table.Columns))
+			}
+			key := fmt.Sprint(row[0])
+			if keys[name][key] {
+				t.Errorf("%s: repeated key %q", name, key)
+			}
+			keys[name][key] = true
+		}
+	}
+
+	// Repeated sense numbers get different keys.
+	for _, key := range []string{"por#5", "por#5~2", "vent#1", "vent#2-1"} {
+		if !keys["senses"][key] {
+			t.Errorf("senses: missing key %q", key)
+		}
+	}
+
+	// Foreign keys refer to existing rows.
+	references := []struct {
+		table, column, target string
+	}{
+		{"senses", "entry_id", "entries"},
+		{"synonyms", "sense_id", "senses"},
+		{"synonyms", "entry_id", "entries"},
+		{"relations", "sense_id", "senses"},
+		{"relations", "entry_id", "entries"},
+		{"idioms", "sense_id", "senses"},
+		{"idioms", "entry_id", "entries"},
+	}
+	for _, ref := range references {
+		table := tables[ref.table]
+		column := -1
+		for i, name := range table.Columns {
+			if name == ref.column {
+				column = i
+			}
+		}
+		if column < 0 {
+			t.Fatalf("%s has no column %s", ref.table, ref.column)
+		}
+		if len(table.Rows) == 0 {
+			t.Errorf("%s has no rows", ref.table)
+		}
+		for _, row := range table.Rows {
+			if value := fmt.Sprint(row[column]); !keys[ref.target][value] {
+				t.Errorf("%s %v: %s %q is not in %s", ref.table, row[0], ref.column, value, ref.target)
+			}
+		}
+	}
+
+	// Relations and idioms under the second sense 5 of "por" belong to it.
+	for _, key := range []string{"por#5~2/related/1", "por#5~2/1"} {
+		if !keys["relations"][key] && !keys["idioms"][key] {
+			t.Errorf("missing relation or idiom %q", key)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Text formats of the pages, besides HTML and JSON.
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+
+	// formatTerminal is the inline content of the terminal text of entries (see EntryTerminalText).
+	formatTerminal = "terminal"
+)
+
+// ANSI escape codes of the emphasis in terminal text.
+const (
+	ansiBold      = "\x1b[1m"
+	ansiBoldEnd   = "\x1b[22m"
+	ansiItalic    = "\x1b[3m"
+	ansiItalicEnd = "\x1b[23m"
+)
+
+// markdownEscaper escapes the characters of plain text that have a meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+// documentWriter converts an HTML fragment to Markdown or plain text. It handles the
+// elements found in the data file: paragraphs, indented divs, dividers, line breaks,
+// emphasis, links and the odd table. Other elements are reduced to their text.
+type documentWriter struct {
+	format  string
+	ansi    bool   // Whether terminal text uses ANSI escape codes for emphasis
+	baseURL string // Prefix of the site-relative links listed as footnotes in terminal text
+	out     strings.Builder
+	line    string   // Inline content of the current block
+	pending string   // Opening markers not yet written, to keep them next to the text
+	links   []string // Targets of the open links
+	indent  int      // Depth of the indented divs
+	divs    []bool   // Whether each open div is indented
+	item    bool     // Whether the current block is a list item
+	last    string   // Kind of the last block written: "item" or "block"
+
+	// footnotes are the targets of the links of terminal text, numbered from 1.
+	footnotes []string
+}
+
+// RenderDocument converts a page with the given title and HTML fragment to Markdown
+// or plain text (see FormatMarkdown and FormatText). Links are kept in Markdown and
+// reduced to their text in plain text.
+func RenderDocument(format, title, fragment string) string {
+	w := &documentWriter{format: format}
+	w.write("<h1>" + title + "</h1>" + fragment)
+	return w.out.String() + "\n"
+}
+
+// EntryDocument renders an entry as Markdown or plain text (see RenderDocument).
+func EntryDocument(data EntryData, format string) string {
+	return RenderDocument(format, data.DisplayTitle, addSenseFragments(data.HTML))
+}
+
+// LetterDocument renders the list of entries starting with a letter as Markdown or
+// plain text (see RenderDocument).
+func LetterDocument(data LetterData, format string) string {
+	var list strings.Builder
+	list.WriteString("<ul>")
+	for _, entry := range data.Entries {
+		fmt.Fprintf(&list, `<li><a href="%s">%s</a></li>`, html.EscapeString(entry.URL), entry.DisplayTitle)
+	}
+	list.WriteString("</ul>")
+	return RenderDocument(format, "Paraules que comencen per "+strings.ToUpper(data.Letter), list.String())
+}
+
+// SemanticFieldDocument renders a semantic field as Markdown or plain text (see RenderDocument).
+func SemanticFieldDocument(data SemanticFieldData, format string) string {
+	return RenderDocument(format, html.EscapeString(data.Title), addSenseFragments(data.HTML))
+}
+
+// write converts the HTML fragment, tag by tag.
+func (w *documentWriter) write(fragment string) {
+	pos := 0
+	for _, match := range tagPattern.FindAllStringIndex(fragment, -1) {
+		w.text(fragment[pos:match[0]])
+		w.tag(fragment[match[0]+1 : match[1]-1])
+		pos = match[1]
+	}
+	w.text(fragment[pos:])
+	w.flush()
+}
+
+// text adds the text between two tags to the current block, collapsing whitespace.
+func (w *documentWriter) text(s string) {
+	s = strings.ReplaceAll(html.UnescapeString(s), "\u00ad", "") // Soft hyphens
+	if s == "" {
+		return
+	}
+	leading := unicode.IsSpace([]rune(s)[0])
+	trailing := strings.TrimRightFunc(s, unicode.IsSpace) != s
+	s = strings.Join(strings.Fields(s), " ")
+	if w.format == FormatMarkdown {
+		s = markdownEscaper.Replace(s)
+	}
+
+	if leading && w.line != "" {
+		w.line += " "
+	}
+	if s == "" {
+		return
+	}
+	w.line += w.pending + s
+	w.pending = ""
+	if trailing {
+		w.line += " "
+	}
+}
+
+// tag handles an opening or closing tag.
+func (w *documentWriter) tag(tag string) {
+	closing := strings.HasPrefix(tag, "/")
+	fields := strings.Fields(strings.TrimPrefix(tag, "/"))
+	if len(fields) == 0 {
+		return
+	}
+	name := strings.ToLower(strings.TrimRight(fields[0], "/"))
+
+	switch name {
+	case "h1":
+		if closing {
+			w.heading()
+		} else {
+			w.flush()
+		}
+	case "p", "h2", "h3", "li", "tr":
+		w.flush()
+		w.item = name == "li" && !closing
+	case "div":
+		w.flush()
+		if !closing {
+			indented := strings.Contains(tag, "indented-content")
+			w.divs = append(w.divs, indented)
+			if indented {
+				w.indent++
+			}
+		} else if len(w.divs) > 0 {
+			if w.divs[len(w.divs)-1] {
+				w.indent--
+			}
+			w.divs = w.divs[:len(w.divs)-1]
+		}
+	case "hr":
+		w.flush()
+		w.block(w.marker("---", "----"))
+	case "br":
+		w.line = strings.TrimRight(w.line, " ") + w.marker("\\", "") + "\n"
+	case "td", "th":
+		if !closing && strings.TrimSpace(w.line) != "" {
+			w.line = strings.TrimRight(w.line, " ") + " | "
+		}
+	case "strong", "b":
+		open, close := w.emphasis(ansiBold, ansiBoldEnd, "**", "*")
+		w.inline(closing, open, close)
+	case "em", "i":
+		open, close := w.emphasis(ansiItalic, ansiItalicEnd, "*", "_")
+		w.inline(closing, open, close)
+	case "a":
+		if w.format == FormatText {
+			return
+		}
+		if !closing {
+			href := ""
+			for _, attr := range attrPattern.FindAllStringSubmatch(tag, -1) {
+				if attr[1] == "href" {
+					href = html.UnescapeString(attr[2])
+				}
+			}
+			w.links = append(w.links, href)
+			if w.format == FormatMarkdown {
+				w.inline(false, "[", "")
+			}
+		} else if len(w.links) > 0 {
+			href := w.links[len(w.links)-1]
+			w.links = w.links[:len(w.links)-1]
+			if w.format == FormatMarkdown {
+				w.inline(true, "[", "]("+strings.ReplaceAll(href, " ", "%20")+")")
+			} else if href != "" {
+				w.footnote(href)
+			}
+		}
+	}
+}
+
+// emphasis returns the opening and closing markers of an emphasis element: ANSI
+// escape codes or plain markers in terminal text, the Markdown marker, or none in
+// plain text.
+func (w *documentWriter) emphasis(ansiOpen, ansiClose, markdown, terminal string) (string, string) {
+	switch {
+	case w.format == formatTerminal && w.ansi:
+		return ansiOpen, ansiClose
+	case w.format == formatTerminal:
+		return terminal, terminal
+	default:
+		return w.marker(markdown, ""), w.marker(markdown, "")
+	}
+}
+
+// footnote adds the footnote number of a link target after the link text, reusing
+// the number of the same target if it was already listed.
+func (w *documentWriter) footnote(href string) {
+	if strings.HasPrefix(href, "/") {
+		href = w.baseURL + href
+	}
+	n := slices.Index(w.footnotes, href) + 1
+	if n == 0 {
+		w.footnotes = append(w.footnotes, href)
+		n = len(w.footnotes)
+	}
+	trimmed := strings.TrimRight(w.line, " ")
+	w.line = trimmed + fmt.Sprintf("[%d]", n) + w.line[len(trimmed):]
+}
+
+// inlineText converts the inner HTML of a paragraph, returning its lines.
+func (w *documentWriter) inlineText(fragment string) string {
+	w.write(fragment)
+	text := w.out.String()
+	w.out.Reset()
+	w.last = ""
+	return text
+}
+
+// marker returns the Markdown or the plain text form of a marker.
+func (w *documentWriter) marker(markdown, text string) string {
+	if w.format == FormatMarkdown {
+		return markdown
+	}
+	return text
+}
+
+// inline opens or closes an inline element. Opening markers wait for the next text,
+// and closing markers are written before any trailing space, as Markdown requires;
+// elements without text are dropped.
+func (w *documentWriter) inline(closing bool, open, close string) {
+	if open == "" && close == "" {
+		return
+	}
+	if !closing {
+		w.pending += open
+		return
+	}
+	if strings.HasSuffix(w.pending, open) {
+		w.pending = strings.TrimSuffix(w.pending, open)
+		return
+	}
+	trimmed := strings.TrimRight(w.line, " ")
+	w.line = trimmed + close + w.line[len(trimmed):]
+}
+
+// heading ends the title, underlining it in plain text.
+func (w *documentWriter) heading() {
+	title := strings.TrimSpace(w.line)
+	w.line, w.pending, w.links = "", "", nil
+	if w.format == FormatMarkdown {
+		title = "# " + title
+	} else {
+		title += "\n" + strings.Repeat("=", len([]rune(title)))
+	}
+	w.block(title)
+}
+
+// flush ends the current block.
+func (w *documentWriter) flush() {
+	line := strings.TrimSuffix(strings.TrimSpace(w.line), " |") // Empty last table cell
+	w.line, w.pending, w.links = "", "", nil
+	if line == "" {
+		return
+	}
+	if w.item {
+		line = "- " + line
+	}
+	w.block(line)
+}
+
+// block writes a block, indented in plain text and quoted in Markdown within
+// indented divs. Consecutive list items are not separated by blank lines.
+func (w *documentWriter) block(content string) {
+	kind := "block"
+	if w.item {
+		kind = "item"
+	}
+	if w.out.Len() > 0 {
+		if kind == "item" && w.last == "item" {
+			w.out.WriteString("\n")
+		} else {
+			w.out.WriteString("\n\n")
+		}
+	}
+	w.last = kind
+
+	prefix := strings.Repeat(w.marker("> ", "    "), w.indent)
+	for i, line := range strings.Split(content, "\n") {
+		if i > 0 {
+			w.out.WriteString("\n")
+		}
+		w.out.WriteString(strings.TrimRight(prefix+strings.TrimSpace(line), " "))
+	}
+}
@@ -0,0 +1,358 @@
+package core
+
+import (
+	"cmp"
+	"html"
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// snippetLength is the approximate length, in bytes, of the snippets of long
+// paragraphs in full-text search results.
+const snippetLength = 240
+
+// TextLocation identifies the part of an entry a paragraph belongs to.
+// All fields are empty for paragraphs outside any sense.
+type TextLocation struct {
+	PartOfSpeech    string `json:"part_of_speech,omitempty"`
+	Sense           string `json:"sense,omitempty"`
+	Subsection      string `json:"subsection,omitempty"` // Subsection letter, e.g. "c"
+	SubsectionTitle string `json:"subsection_title,omitempty"`
+	Anchor          string `json:"anchor,omitempty"` // Id of the sense or subsection in the entry page
+}
+
+// TextMatch represents a paragraph of an entry matching a full-text query.
+type TextMatch struct {
+	TextLocation
+
+	// Snippet is the text of the paragraph, or part of it for long paragraphs,
+	// with the matched words in <mark> elements.
+	Snippet template.HTML `json:"snippet"`
+}
+
+// TextSearchResult represents an entry matching a full-text query.
+type TextSearchResult struct {
+	Slug         string        `json:"slug"`
+	DisplayTitle template.HTML `json:"title"`
+	URL          string        `json:"url"`
+	Matches      []TextMatch   `json:"matches"`
+}
+
+// TextIndexParagraph is a paragraph of the full-text index, in the data of the
+// static search. Keys are kept short as in the entries search data (see SearchItem).
+type TextIndexParagraph struct {
+	Entry           int    `json:"e"` // Index of the entry in the search data (see GetSearchData)
+	Anchor          string `json:"a,omitempty"`
+	PartOfSpeech    string `json:"p,omitempty"`
+	Sense           string `json:"n,omitempty"`
+	Subsection      string `json:"x,omitempty"`
+	SubsectionTitle string `json:"h,omitempty"`
+	Text            string `json:"t"`
+}
+
+// textIndex is an inverted index of the words of the entry contents.
+type textIndex struct {
+	paragraphs []textParagraph
+	// postings maps each normalized word to its occurrences, sorted by paragraph and position.
+	postings map[string][]textPosting
+}
+
+// textParagraph is a paragraph of an entry, in plain text.
+type textParagraph struct {
+	entry    int // Index in AllEntries
+	location TextLocation
+	text     string
+}
+
+// textPosting is an occurrence of a word: the index of the paragraph and the
+// position of the word in the paragraph.
+type textPosting struct {
+	paragraph int
+	position  int
+}
+
+// textToken is a word of a text, with its byte offsets in the text.
+type textToken struct {
+	word       string // Normalized word
+	start, end int
+}
+
+// buildTextIndex indexes the paragraphs of all entries: sense headers, subsection
+// paragraphs and notes. It must be called after the entries are parsed into senses.
+func buildTextIndex() textIndex {
+	index := textIndex{postings: make(map[string][]textPosting)}
+	add := func(entry int, location TextLocation, content string) {
+		text := plainText(content)
+		if text == "" {
+			return
+		}
+		paragraph := len(index.paragraphs)
+		index.paragraphs = append(index.paragraphs, textParagraph{entry: entry, location: location, text: text})
+		for position, token := range tokenize(text) {
+			index.postings[token.word] = append(index.postings[token.word], textPosting{paragraph, position})
+		}
+	}
+
+	for i, entry := range AllEntries {
+		for _, note := range entry.Notes {
+			add(i, TextLocation{}, note)
+		}
+		for _, sense := range entry.Senses {
+			location := TextLocation{
+				PartOfSpeech: sense.PartOfSpeech,
+				Sense:        sense.Number,
+				Anchor:       sense.Anchor(),
+			}
+			add(i, location, sense.HeaderHTML)
+			for _, subsection := range sense.Subsections {
+				location := location
+				if subsection.Letter != "" {
+					location.Subsection = subsection.Letter
+					location.SubsectionTitle = subsection.Title
+					location.Anchor = sense.Anchor() + subsection.Letter
+				}
+				for _, p := range subsection.Paragraphs {
+					add(i, location, p)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// tokenize splits a text into its words, normalized with catalan.Normalize.
+// Words are sequences of letters and digits, including the middle dot of "l·l";
+// apostrophes and hyphens separate words, e.g. "adonar-se" gives "adonar" and "se".
+func tokenize(text string) []textToken {
+	var tokens []textToken
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) ||
+			(r == '·' && start >= 0 && i+len("·") < len(text) && unicode.IsLetter(rune(text[i+len("·")])))
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, textToken{catalan.Normalize(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, textToken{catalan.Normalize(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// parseTextQuery splits a full-text query into its clauses, each a sequence of
+// normalized words that must appear consecutively. Quoted text is a phrase, and
+// so is each unquoted term made of several words (e.g. "adonar-se").
+func parseTextQuery(query string) [][]string {
+	var clauses [][]string
+	addClause := func(text string) {
+		var words []string
+		for _, token := range tokenize(catalan.Canonicalize(text)) {
+			words = append(words, token.word)
+		}
+		if len(words) > 0 {
+			clauses = append(clauses, words)
+		}
+	}
+
+	query = strings.NewReplacer("«", `"`, "»", `"`, "“", `"`, "”", `"`).Replace(query)
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			addClause(part)
+			continue
+		}
+		for _, term := range strings.Fields(part) {
+			addClause(term)
+		}
+	}
+	return clauses
+}
+
+// findPhrase returns the occurrences of the first word of a phrase followed by the
+// rest of the words of the phrase.
+func (index textIndex) findPhrase(words []string) []textPosting {
+	matches := index.postings[words[0]]
+	for offset, word := range words[1:] {
+		next := make(map[textPosting]bool, len(index.postings[word]))
+		for _, p := range index.postings[word] {
+			next[p] = true
+		}
+		matches = slices.DeleteFunc(slices.Clone(matches), func(p textPosting) bool {
+			return !next[textPosting{p.paragraph, p.position + offset + 1}]
+		})
+	}
+	return matches
+}
+
+// SearchText returns the entries whose content contains every term of the query,
+// in any of their paragraphs. Terms are words, ignoring case and accents, or
+// phrases between double quotes. Each result lists the matching paragraphs with
+// highlighted snippets. Entries with more matching paragraphs come first, then
+// entries are in dictionary order.
+func SearchText(query string) []TextSearchResult {
+	clauses := parseTextQuery(query)
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	// Positions of the matched words in each paragraph, and the clauses matched by each entry.
+	highlights := make(map[int]map[int]bool)
+	clausesByEntry := make(map[int]map[int]bool)
+	for c, words := range clauses {
+		for _, p := range fullTextIndex.findPhrase(words) {
+			if highlights[p.paragraph] == nil {
+				highlights[p.paragraph] = make(map[int]bool)
+			}
+			for k := range words {
+				highlights[p.paragraph][p.position+k] = true
+			}
+			entry := fullTextIndex.paragraphs[p.paragraph].entry
+			if clausesByEntry[entry] == nil {
+				clausesByEntry[entry] = make(map[int]bool)
+			}
+			clausesByEntry[entry][c] = true
+		}
+	}
+
+	resultIndexByEntry := make(map[int]int)
+	var results []TextSearchResult
+	for _, paragraph := range slices.Sorted(maps.Keys(highlights)) {
+		p := fullTextIndex.paragraphs[paragraph]
+		if len(clausesByEntry[p.entry]) < len(clauses) {
+			continue
+		}
+		i, ok := resultIndexByEntry[p.entry]
+		if !ok {
+			entry := AllEntries[p.entry]
+			results = append(results, TextSearchResult{
+				Slug:         entry.Slug,
+				DisplayTitle: template.HTML(entry.DisplayTitle),
+				URL:          EntryPath(entry.Slug),
+			})
+			i = len(results) - 1
+			resultIndexByEntry[p.entry] = i
+		}
+		results[i].Matches = append(results[i].Matches, TextMatch{
+			TextLocation: p.location,
+			Snippet:      template.HTML(renderSnippet(p.text, highlights[paragraph])),
+		})
+	}
+
+	// Paragraphs are indexed in dictionary order, so a stable sort keeps it for ties.
+	slices.SortStableFunc(results, func(a, b TextSearchResult) int {
+		return cmp.Compare(len(b.Matches), len(a.Matches))
+	})
+	return results
+}
+
+// TextIndexShard returns the shard of the static search index holding a normalized
+// word: its first two letters or digits, or "_" if it has none (e.g. "d'aigua"
+// is in shard "da"). js/search-text.js computes the same shards.
+func TextIndexShard(word string) string {
+	var shard []rune
+	for _, r := range word {
+		if len(shard) < 2 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			shard = append(shard, r)
+		}
+	}
+	if len(shard) == 0 {
+		return "_"
+	}
+	return string(shard)
+}
+
+// GetTextIndexShards returns the words of the full-text index grouped by shard
+// (see TextIndexShard), for the static search. The occurrences of each word are
+// flattened into entry, paragraph and position triples, sorted by paragraph.
+func GetTextIndexShards() map[string]map[string][]int {
+	shards := make(map[string]map[string][]int)
+	for word, postings := range fullTextIndex.postings {
+		shard := TextIndexShard(word)
+		if shards[shard] == nil {
+			shards[shard] = make(map[string][]int)
+		}
+		flat := make([]int, 0, 3*len(postings))
+		for _, p := range postings {
+			flat = append(flat, fullTextIndex.paragraphs[p.paragraph].entry, p.paragraph, p.position)
+		}
+		shards[shard][word] = flat
+	}
+	return shards
+}
+
+// GetTextIndexParagraphs returns the paragraphs of the full-text index, for the static search.
+func GetTextIndexParagraphs() []TextIndexParagraph {
+	paragraphs := make([]TextIndexParagraph, 0, len(fullTextIndex.paragraphs))
+	for _, p := range fullTextIndex.paragraphs {
+		paragraphs = append(paragraphs, TextIndexParagraph{
+			Entry:           p.entry,
+			Anchor:          p.location.Anchor,
+			PartOfSpeech:    p.location.PartOfSpeech,
+			Sense:           p.location.Sense,
+			Subsection:      p.location.Subsection,
+			SubsectionTitle: p.location.SubsectionTitle,
+			Text:            p.text,
+		})
+	}
+	return paragraphs
+}
+
+// renderSnippet returns the HTML of a paragraph text with the words at the given
+// positions in <mark> elements. Long texts are cut around the first highlighted
+// word, at word boundaries.
+func renderSnippet(text string, positions map[int]bool) string {
+	tokens := tokenize(text)
+	first := len(text)
+	for position := range positions {
+		if position < len(tokens) {
+			first = min(first, tokens[position].start)
+		}
+	}
+
+	start, end := 0, len(text)
+	if len(text) > snippetLength {
+		start = max(0, first-snippetLength/4)
+		end = min(len(text), start+snippetLength)
+		if i := strings.IndexByte(text[start:first], ' '); start > 0 && i >= 0 {
+			start += i + 1
+		}
+		if i := strings.LastIndexByte(text[first:end], ' '); end < len(text) && i >= 0 {
+			end = first + i
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	last := start
+	for position, token := range tokens {
+		if !positions[position] || token.start < start || token.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:token.start]))
+		b.WriteString("<mark>" + html.EscapeString(text[token.start:token.end]) + "</mark>")
+		last = token.end
+	}
+	b.WriteString(html.EscapeString(text[last:end]))
+	if end < len(text) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "[]"},
+		{"  ", "[]"},
+		{"vent aire", "[[vent] [aire]]"},
+		{"Àbril", "[[abril]]"},
+		{`"cop de vent"`, "[[cop de vent]]"},
+		{`«cop de  vent» Ventada`, "[[cop de vent] [ventada]]"},
+		{`“cop de vent”`, "[[cop de vent]]"},
+		{`"cop de vent`, "[[cop de vent]]"}, // Unclosed quotes run to the end
+		{`"" vent`, "[[vent]]"},
+		{"d'abril", "[[d abril]]"},
+		{"l’abril", "[[l abril]]"},
+		{"adonar-se", "[[adonar se]]"},
+		{"col·lecció", "[[col·leccio]]"},
+		{"... ?", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(parseTextQuery(tt.query)); got != tt.want {
+			t.Errorf("parseTextQuery(%q) = %s; want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFindPhrase(t *testing.T) {
+	index := textIndex{postings: make(map[string][]textPosting)}
+	for paragraph, text := range []string{"cop de vent, vent de cop", "cop de", "vent"} {
+		for position, token := range tokenize(text) {
+			index.postings[token.word] = append(index.postings[token.word], textPosting{paragraph, position})
+		}
+	}
+
+	tests := []struct {
+		phrase string
+		want   string
+	}{
+		{"vent", "[{0 2} {0 3} {2 0}]"},
+		{"cop de", "[{0 0} {1 0}]"},
+		{"cop de vent", "[{0 0}]"},
+		{"vent vent", "[{0 2}]"},
+		{"de vent", "[{0 1}]"},
+		{"vent cop", "[]"},    // Not adjacent
+		{"de vent cop", "[]"}, // Not adjacent
+		{"de cop de", "[]"},   // Across paragraphs
+		{"aire", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(index.findPhrase(strings.Fields(tt.phrase))); got != tt.want {
+			t.Errorf("findPhrase(%q) = %s; want %s", tt.phrase, got, tt.want)
+		}
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	loadTestData(t)
+
+	tests := []struct {
+		query string
+		want  []string // Slug and the anchors and snippets of its matches
+	}{
+		{"", nil},
+		{"xyz", nil},
+		{"aire", []string{
+			"por",
+			"5c: Rel.: <mark>aire</mark>",
+			"vent",
+			"1: 1. [cat. val.] <mark>aire</mark>",
+		}},
+		// Entries with more matching paragraphs come first.
+		{"abril", []string{
+			"abril",
+			"1d: A l&#39;<mark>abril</mark>, cada gota en val mil (o val per mil) Importància de la pluja al mes d&#39;<mark>abril</mark>.",
+			"2a: En aquesta accepció prenem el mes d&#39;<mark>abril</mark> com a símbol de la primavera. Ex.: Temps d&#39;<mark>abril</mark>, temps de flors.",
+			"trist_|_trista",
+			"1: 1. abatut, <mark>abril</mark>",
+		}},
+		// Entries must contain every term, in any paragraph.
+		{"Abril TARDOR", []string{
+			"abril",
+			"1d: A l&#39;<mark>abril</mark>, cada gota en val mil (o val per mil) Importància de la pluja al mes d&#39;<mark>abril</mark>.",
+			"2a: En aquesta accepció prenem el mes d&#39;<mark>abril</mark> com a símbol de la primavera. Ex.: Temps d&#39;<mark>abril</mark>, temps de flors.",
+			"2c: Ant.: <mark>tardor</mark>, primavera d&#39;hivern",
+		}},
+		{"abril ventada", nil},
+		// Phrases match adjacent words only.
+		{`"cop de vent"`, []string{
+			"aire",
+			"1: 1. vent, ventada, <mark>cop</mark> <mark>de</mark> <mark>vent</mark>",
+		}},
+		{`"vent cop"`, nil},
+		{"l'abril", []string{
+			"abril",
+			"1d: A <mark>l</mark>&#39;<mark>abril</mark>, cada gota en val mil (o val per mil) Importància de la pluja al mes d&#39;abril.",
+		}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, result := range SearchText(tt.query) {
+			got = append(got, result.Slug)
+			for _, match := range result.Matches {
+				got = append(got, match.Anchor+": "+string(match.Snippet))
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("SearchText(%q) =\n%s\nwant\n%s", tt.query, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestRenderSnippet(t *testing.T) {
+	long := strings.Repeat("abans ", 50) + "vent " + strings.Repeat("després ", 50)
+
+	tests := []struct {
+		text      string
+		positions []int
+		want      string
+	}{
+		{"el vent bufa", nil, "el vent bufa"},
+		{"el vent bufa", []int{1}, "el <mark>vent</mark> bufa"},
+		{"cop de vent", []int{0, 1, 2}, "<mark>cop</mark> <mark>de</mark> <mark>vent</mark>"},
+		{"d'abril <i>", []int{1}, "d&#39;<mark>abril</mark> &lt;i&gt;"},
+		{"el vent", []int{5}, "el vent"}, // Positions past the end are ignored
+		// Long texts are cut at word boundaries around the first highlighted word.
+		{
+			long, []int{50},
+			"… " + strings.Repeat("abans ", 9) + "<mark>vent</mark> " + strings.Repeat("després ", 18) + "després …",
+		},
+	}
+	for _, tt := range tests {
+		positions := make(map[int]bool)
+		for _, p := range tt.positions {
+			positions[p] = true
+		}
+		if got := renderSnippet(tt.text, positions); got != tt.want {
+			t.Errorf("renderSnippet(%.30q, %v) =\n%q\nwant\n%q", tt.text, tt.positions, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// glossaryRefPattern matches the reference written after a link in the glossary:
+// an optional grammatical category, sense numbers with subsection letters, and the
+// CS (camp semàntic), Der (derivats) or Ant (antònims) markers, e.g. "v. tr. 1c CS",
+// "1 i 2c", "1c CS, 3" or "Der".
+var glossaryRefPattern = regexp.MustCompile(
+	`^(?:\(?(?:[a-z]+\.|pron|tr|intr|i)\)?\s*)*` +
+		`(?:\d+(?:\s?[a-f]\b)?(?:\s+(?i:CS|Der|Ant|Rel)\b)*` +
+		`(?:\s*(?:,|i|o)\s*\d+(?:\s?[a-f]\b)?(?:\s+(?i:CS|Der|Ant|Rel)\b)*)*` +
+		`|(?i:CS|Der|Ant|Rel)\b)`)
+
+// glossaryRefContentPattern matches the parts that make a glossary reference meaningful.
+var glossaryRefContentPattern = regexp.MustCompile(`\d|(?i:CS|Der|Ant|Rel)`)
+
+// glossaryNotePattern matches the notes written after a glossary term, such as
+// "(m.)" or "[cat. val.]", and glossaryAlternativePattern the alternative forms,
+// such as "(o mè)". They are shown in regular weight in the glossary search.
+var (
+	glossaryNotePattern        = regexp.MustCompile(`\([^()]*\)|\[[^\[\]]*\]`)
+	glossaryAlternativePattern = regexp.MustCompile(`^\(o ([^()]*)\)$`)
+)
+
+// placeholderPattern matches the link placeholders used while extracting the synonym phrase.
+var placeholderPattern = regexp.MustCompile("\x00(\\d+)\x00")
+
+// CheckGlossaryTermID reports why a glossary term id cannot be the path of its page
+// (see GlossaryTermPath), or nil if it can. Ids may contain slashes, e.g.
+// "abreviació/abreviatura", whose pages are written in subdirectories of the static
+// site, but no empty, "." or ".." segments, and they cannot start with "lletra/",
+// the path of the glossary letter pages.
+func CheckGlossaryTermID(id string) error {
+	segments := strings.Split(id, "/")
+	if len(segments) > 1 && segments[0] == "lletra" {
+		return errors.New(`id starting with "lletra/"`)
+	}
+	for _, segment := range segments {
+		switch segment {
+		case "":
+			return errors.New("empty id or path segment")
+		case ".", "..":
+			return fmt.Errorf("%q path segment", segment)
+		}
+	}
+	return nil
+}
+
+// parseGlossary parses the glossary HTML of every letter into terms, sorted by
+// letter and in content order within each letter.
+func parseGlossary(glossary map[string]template.HTML) []GlossaryTerm {
+	var terms []GlossaryTerm
+	for _, letter := range slices.Sorted(maps.Keys(glossary)) {
+		for _, p := range splitBlocks(string(glossary[letter])) {
+			if p.Tag != "p" {
+				continue
+			}
+			terms = append(terms, parseGlossaryTerm(letter, p.ID, p.Inner))
+		}
+	}
+	return terms
+}
+
+// parseGlossaryTerm parses a glossary paragraph such as
+// `abatut — <a href="/lema/trist_%7C_trista">trist</a> 1 i 2c`.
+func parseGlossaryTerm(letter, id, content string) GlossaryTerm {
+	term := GlossaryTerm{
+		ID:     html.UnescapeString(id),
+		Letter: letter,
+		HTML:   template.HTML(content),
+	}
+
+	head, body, found := strings.Cut(content, "—")
+	if !found {
+		body, head = head, ""
+	}
+	term.Term = plainText(head)
+
+	// Replace each link and its reference with a placeholder, so the
+	// parenthesized groups holding only references can be removed afterwards.
+	var text strings.Builder
+	matches := linkPattern.FindAllStringSubmatchIndex(body, -1)
+	last := 0
+	for i, m := range matches {
+		text.WriteString(body[last:m[0]])
+
+		tailEnd := len(body)
+		if i+1 < len(matches) {
+			tailEnd = matches[i+1][0]
+		}
+		reference, consumed := parseGlossaryRef(body[m[1]:tailEnd])
+
+		target := GlossaryTarget{
+			Title:     plainText(body[m[4]:m[5]]),
+			Reference: reference,
+		}
+		target.Slug, _ = resolveEntrySlug(strings.TrimPrefix(body[m[2]:m[3]], "/lema/"))
+		if i := strings.IndexFunc(reference, unicode.IsDigit); i >= 0 {
+			target.PartOfSpeech = strings.Trim(reference[:i], "() ")
+			if sense := senseRefPattern.FindStringSubmatch(reference[i:]); sense != nil {
+				target.Sense = sense[1] + sense[2]
+			}
+		}
+		term.Targets = append(term.Targets, target)
+
+		text.WriteString("\x00" + strconv.Itoa(i) + "\x00")
+		last = m[1] + consumed
+	}
+	text.WriteString(body[last:])
+
+	term.Synonyms = glossarySynonyms(plainText(text.String()), term.Targets)
+
+	return term
+}
+
+// parseGlossaryRef returns the reference written after a glossary link and the
+// number of bytes of HTML it spans. Suffixes and endings of the linked word, such
+// as "(-se)" in "reunir</a>(-se) 5", are kept out of the reference.
+func parseGlossaryRef(tail string) (string, int) {
+	// Work on the HTML directly to be able to report the consumed length,
+	// skipping tags and entities as whitespace.
+	var text strings.Builder
+	var offsets []int
+	for i := 0; i < len(tail); {
+		switch {
+		case tail[i] == '<':
+			end := strings.IndexByte(tail[i:], '>')
+			if end < 0 {
+				end = len(tail) - i - 1
+			}
+			i += end + 1
+			continue
+		case strings.HasPrefix(tail[i:], "&nbsp;"):
+			text.WriteByte(' ')
+			offsets = append(offsets, i)
+			i += len("&nbsp;")
+			continue
+		}
+		text.WriteByte(tail[i])
+		offsets = append(offsets, i)
+		i++
+	}
+	plain := text.String()
+
+	start := 0
+	if plain != "" && !unicode.IsSpace(rune(plain[0])) {
+		// Skip the suffix attached to the linked word.
+		start = strings.IndexFunc(plain, unicode.IsSpace)
+		if start < 0 || strings.ContainsAny(plain[:start], ",;") {
+			return "", 0
+		}
+	}
+	for start < len(plain) && plain[start] == ' ' {
+		start++
+	}
+	if strings.HasPrefix(plain[start:], "-") {
+		// Skip the ending of the linked word, e.g. "desert</a> -a 1".
+		end := strings.IndexByte(plain[start:], ' ')
+		if end < 0 {
+			return "", 0
+		}
+		start += end
+		for start < len(plain) && plain[start] == ' ' {
+			start++
+		}
+	}
+
+	loc := glossaryRefPattern.FindStringIndex(plain[start:])
+	if loc == nil {
+		return "", 0
+	}
+	reference := strings.TrimSpace(plain[start : start+loc[1]])
+	if !glossaryRefContentPattern.MatchString(reference) {
+		return "", 0
+	}
+
+	end := start + loc[1]
+	consumed := len(tail)
+	if end < len(offsets) {
+		consumed = offsets[end]
+	}
+	return reference, consumed
+}
+
+// glossarySynonyms returns the synonym phrase of a glossary term, given the plain
+// text of its definition with links replaced by placeholders. Parenthesized groups
+// that only hold links are dropped, and the remaining links are replaced by their
+// titles, e.g. "desagradable (\x000\x00); odiós (\x001\x00)" becomes "desagradable; odiós".
+func glossarySynonyms(text string, targets []GlossaryTarget) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(text, '(')
+		if open < 0 {
+			b.WriteString(text)
+			break
+		}
+		closeIndex := strings.IndexByte(text[open:], ')')
+		if closeIndex < 0 {
+			b.WriteString(text)
+			break
+		}
+		group := text[open+1 : open+closeIndex]
+		rest := placeholderPattern.ReplaceAllString(group, "")
+		if strings.Trim(rest, " ,;/") == "" && group != rest {
+			b.WriteString(text[:open])
+		} else {
+			b.WriteString(text[:open+closeIndex+1])
+		}
+		text = text[open+closeIndex+1:]
+	}
+
+	synonyms := placeholderPattern.ReplaceAllStringFunc(b.String(), func(placeholder string) string {
+		i, err := strconv.Atoi(strings.Trim(placeholder, "\x00"))
+		if err != nil || i >= len(targets) {
+			return ""
+		}
+		return targets[i].Title
+	})
+	synonyms = strings.Join(strings.Fields(synonyms), " ")
+	synonyms = strings.ReplaceAll(synonyms, " ;", ";")
+	synonyms = strings.ReplaceAll(synonyms, " ,", ",")
+	return strings.Trim(synonyms, " ,;")
+}
+
+// GetGlossaryTerms returns the glossary terms with the given id.
+// Ids are not unique in the export: homonyms such as "ban" have one term per meaning.
+func GetGlossaryTerms(id string) []GlossaryTerm {
+	var terms []GlossaryTerm
+	for _, i := range glossaryTermIndexesByID[id] {
+		terms = append(terms, GlossaryTerms[i])
+	}
+	return terms
+}
+
+// GetGlossarySearchData returns the data used by the glossary search, with a
+// single item for the terms sharing the same id, in glossary order.
+func GetGlossarySearchData() []GlossarySearchItem {
+	var items []GlossarySearchItem
+	seen := make(map[string]bool, len(GlossaryTerms))
+	for _, term := range GlossaryTerms {
+		if seen[term.ID] {
+			continue
+		}
+		seen[term.ID] = true
+		items = append(items, GlossarySearchItem{
+			ID:      term.ID,
+			Display: glossaryDisplayTerm(term.Term),
+			Search:  catalan.Normalize(term.Term),
+		})
+	}
+	return items
+}
+
+// glossaryDisplayTerm returns the HTML of a glossary term for the glossary search,
+// with its notes and the markers of its alternative forms in regular weight, as in
+// entry display titles, e.g. `aglutinar<span class="no-bold">(-se)</span>`.
+func glossaryDisplayTerm(term string) string {
+	return glossaryNotePattern.ReplaceAllStringFunc(html.EscapeString(term), func(note string) string {
+		if m := glossaryAlternativePattern.FindStringSubmatch(note); m != nil {
+			return `<span class="no-bold">(o</span> ` + m[1] + `<span class="no-bold">)</span>`
+		}
+		return `<span class="no-bold">` + note + `</span>`
+	})
+}
+
+// GetGlossaryTermsByLetter returns the glossary terms listed under the given uppercase letter.
+func GetGlossaryTermsByLetter(letter string) []GlossaryTerm {
+	var terms []GlossaryTerm
+	for _, term := range GlossaryTerms {
+		if term.Letter == letter {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// GetGlossaryNavigationLetters returns the previous and next letters of the glossary.
+// Returns empty strings for prev/next if at the beginning/end of the glossary.
+func GetGlossaryNavigationLetters(letter string) (string, string) {
+	letters := slices.Sorted(maps.Keys(Glossary))
+	i := slices.Index(letters, letter)
+	if i < 0 {
+		return "", ""
+	}
+
+	var prev, next string
+	if i > 0 {
+		prev = letters[i-1]
+	}
+	if i < len(letters)-1 {
+		next = letters[i+1]
+	}
+
+	return prev, next
+}
+
+// expandGlossaryTerm returns the view of a glossary term with the text of the
+// senses referenced by its targets.
+func expandGlossaryTerm(term GlossaryTerm) GlossaryTermView {
+	view := GlossaryTermView{GlossaryTerm: term}
+	view.HTML = template.HTML(addSenseFragments(string(term.HTML)))
+	for _, target := range term.Targets {
+		targetView := GlossaryTargetView{GlossaryTarget: target}
+		if i, ok := entryIndexBySlug[target.Slug]; ok {
+			targetView.EntryTitle = template.HTML(AllEntries[i].DisplayTitle)
+			targetView.Anchor = senseAnchor(target.Slug, target.PartOfSpeech, target.Sense)
+			number, letter := splitSenseRef(target.Sense)
+			if sense, ok := GetSense(target.Slug, target.PartOfSpeech, number); ok {
+				targetView.SenseHTML = template.HTML(renderSenseExcerpt(sense, letter))
+			}
+		}
+		view.ExpandedTargets = append(view.ExpandedTargets, targetView)
+	}
+	return view
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"html/template"
+	"reflect"
+	"testing"
+)
+
+func TestParseGlossaryRef(t *testing.T) {
+	tests := []struct {
+		tail      string
+		reference string
+		consumed  int
+	}{
+		{" 1 i 2c; altre", "1 i 2c", len(" 1 i 2c")},
+		{" v. tr. 1c CS", "v. tr. 1c CS", len(" v. tr. 1c CS")},
+		{" Der", "Der", len(" Der")},
+		{"(-se) 5", "5", len("(-se) 5")},
+		{" -a 1, paraula", "1", len(" -a 1")}, // Ending of the linked word
+		{"&nbsp;3", "3", len("&nbsp;3")},
+		{" <em>1</em>", "1", len(" <em>1</em>")},
+		{", altre", "", 0},
+		{" paraula", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		reference, consumed := parseGlossaryRef(tt.tail)
+		if reference != tt.reference || consumed != tt.consumed {
+			t.Errorf("parseGlossaryRef(%q) = %q, %d; want %q, %d", tt.tail, reference, consumed, tt.reference, tt.consumed)
+		}
+	}
+}
+
+func TestParseGlossaryTerm(t *testing.T) {
+	tests := []struct {
+		content string
+		want    GlossaryTerm
+	}{
+		{
+			`abatut — <a href="/lema/trist_%7C_trista">trist</a> 1 i 2c`,
+			GlossaryTerm{
+				Term:     "abatut",
+				Synonyms: "trist",
+				Targets: []GlossaryTarget{
+					{Slug: "trist_|_trista", Title: "trist", Reference: "1 i 2c", Sense: "1"},
+				},
+			},
+		},
+		{
+			`odi — desagradable (<a href="/lema/odi%C3%B3s">odiós</a> v. tr. 2); <a href="/lema/aire">aire</a> CS`,
+			GlossaryTerm{
+				Term:     "odi",
+				Synonyms: "desagradable; aire",
+				Targets: []GlossaryTarget{
+					{Slug: "odiós", Title: "odiós", Reference: "v. tr. 2", PartOfSpeech: "v. tr.", Sense: "2"},
+					{Slug: "aire", Title: "aire", Reference: "CS"},
+				},
+			},
+		},
+		{
+			`sense guió`,
+			GlossaryTerm{Synonyms: "sense guió"},
+		},
+	}
+	for _, tt := range tests {
+		got := parseGlossaryTerm("A", "id", tt.content)
+		tt.want.ID, tt.want.Letter, tt.want.HTML = "id", "A", template.HTML(tt.content)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGlossaryTerm(%q) =\n%+v; want\n%+v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParseGlossary(t *testing.T) {
+	glossary := map[string]template.HTML{
+		"B": `<p id="bo">bo — <a href="/lema/bo">bo</a> 1</p>`,
+		"A": `<p id="abatut">abatut — <a href="/lema/trist">trist</a> 2</p><hr><p id="aire">aire — vent</p>`,
+	}
+	var got []string
+	for _, term := range parseGlossary(glossary) {
+		got = append(got, term.Letter+":"+term.ID)
+	}
+	if want := []string{"A:abatut", "A:aire", "B:bo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGlossary() = %q; want %q", got, want)
+	}
+}
+
+func TestCheckGlossaryTermID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"abatut", true},
+		{"abreviació/abreviatura", true},
+		{"lletra", true},
+		{"lletres/a", true},
+		{"", false},
+		{"/abatut", false},
+		{"abatut/", false},
+		{"a//b", false},
+		{".", false},
+		{"..", false},
+		{"../../etc/passwd", false},
+		{"a/./b", false},
+		{"a/..", false},
+		{"lletra/a", false},
+	}
+	for _, tt := range tests {
+		if err := CheckGlossaryTermID(tt.id); (err == nil) != tt.valid {
+			t.Errorf("CheckGlossaryTermID(%q) = %v; want valid %v", tt.id, err, tt.valid)
+		}
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"html"
 	"html/template"
 	"log"
 	"maps"
 	"os"
 	"slices"
 	"strings"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
 )
 
 // GetServerAddress returns the server address from the PORT env variable.
@@ -30,11 +33,26 @@ func Init() error {
 	}
 
 	log.Printf("Loaded %d entries, %d semantic fields, and glossary.\n", len(AllEntries), len(SemanticFields))
+	for _, link := range GetBrokenLinks() {
+		log.Printf("warning: broken link in %s: %s\n", link.SourcePath(), link.Href)
+	}
+	if len(FixedNormalizedTitles) > 0 {
+		log.Printf("warning: derived the normalized title of %d entries missing or wrong in the data file\n", len(FixedNormalizedTitles))
+	}
+	if len(UnsortedEntries) > 0 {
+		log.Printf("warning: sorted %d entries out of Catalan collation order in the data file\n", len(UnsortedEntries))
+	}
 
+	return ParseTemplates()
+}
+
+// ParseTemplates parses the embedded HTML templates into MainTemplate.
+func ParseTemplates() error {
 	funcMap := template.FuncMap{
 		"upper": strings.ToUpper,
 		"lower": strings.ToLower,
 	}
+	var err error
 	MainTemplate, err = template.New("main.html").Funcs(funcMap).ParseFS(templateFS, "templates/*.html", "templates/partials/*.html")
 	if err != nil {
 		return fmt.Errorf("failed to initialize templates: %w", err)
@@ -44,7 +62,11 @@ func Init() error {
 }
 
 // LoadDataFromFile loads and processes all dictionary data from a gzipped JSON file.
-// It populates the global variables: AllEntries, SemanticFields, DictionaryLetters, and Glossary.
+// It populates the global variables: AllEntries, SemanticFields, DictionaryLetters, Glossary,
+// GlossaryTerms, Idioms, and Links.
+// Entry contents are also parsed into their senses (see Sense) and lexical relations (see Relation).
+// Normalized titles and the entry order are checked against the titles, and derived
+// from them if the export is missing or wrong (see FixedNormalizedTitles and UnsortedEntries).
 // This function is called once at startup.
 func LoadDataFromFile(filePath string) error {
 	file, err := os.Open(filePath)
@@ -72,6 +94,25 @@ func LoadDataFromFile(filePath string) error {
 	AllEntries = data.Entries
 	SemanticFields = data.SemanticFields
 
+	FixedNormalizedTitles = make(map[string]string)
+	for i, entry := range AllEntries {
+		normalized := normalizeTitle(entry.Slug)
+		if entry.NormalizedTitle != normalized {
+			FixedNormalizedTitles[entry.Slug] = entry.NormalizedTitle
+			AllEntries[i].NormalizedTitle = normalized
+		}
+	}
+
+	UnsortedEntries = nil
+	for i := 1; i < len(AllEntries); i++ {
+		if compareEntries(AllEntries[i-1], AllEntries[i]) > 0 {
+			UnsortedEntries = append(UnsortedEntries, AllEntries[i].Slug)
+		}
+	}
+	if len(UnsortedEntries) > 0 {
+		slices.SortStableFunc(AllEntries, compareEntries)
+	}
+
 	// Convert glossary strings to template.HTML to prevent escaping
 	Glossary = make(map[string]template.HTML, len(data.Glossary))
 	for letter, content := range data.Glossary {
@@ -86,22 +127,59 @@ func LoadDataFromFile(filePath string) error {
 	letterMap := make(map[string]bool)
 	for i, entry := range AllEntries {
 		entryIndexBySlug[entry.Slug] = i
-		if len(entry.NormalizedTitle) > 0 {
-			firstLetter := string(entry.NormalizedTitle[0])
-			letterMap[firstLetter] = true
+		AllEntries[i].Senses, AllEntries[i].Notes = parseEntryContent(entry.Content)
+		if letter := catalan.FirstLetter(entry.NormalizedTitle); letter != "" {
+			letterMap[letter] = true
 		}
 	}
 	DictionaryLetters = slices.Sorted(maps.Keys(letterMap))
 
+	// Glossary references are resolved against the entry index, so it must be built first
+	GlossaryTerms = parseGlossary(Glossary)
+	glossaryTermIndexesByID = make(map[string][]int, len(GlossaryTerms))
+	for i, term := range GlossaryTerms {
+		glossaryTermIndexesByID[term.ID] = append(glossaryTermIndexesByID[term.ID], i)
+	}
+
+	// Related words are resolved against entry titles, so all entries must be parsed first
+	entrySlugsByWord := buildEntrySlugsByWord()
+	for i, entry := range AllEntries {
+		AllEntries[i].Relations = parseRelations(entry, entrySlugsByWord)
+	}
+
+	synonymSourcesByWord = buildSynonymSources()
+	suffixIndex = buildSuffixIndex()
+
+	Idioms = nil
+	for _, entry := range AllEntries {
+		Idioms = append(Idioms, parseIdioms(entry)...)
+	}
+
+	Links = buildLinks()
+	backlinksBySlug = buildBacklinks()
+
+	fullTextIndex = buildTextIndex()
+
 	return nil
 }
 
+// normalizeTitle returns the normalized title of an entry from its slug, as
+// title_normalized is exported: with spaces instead of underscores, see catalan.Normalize.
+func normalizeTitle(slug string) string {
+	return catalan.Normalize(strings.ReplaceAll(slug, "_", " "))
+}
+
+// compareEntries compares two entries by title in Catalan collation order.
+func compareEntries(a, b Entry) int {
+	return catalan.Compare(a.Slug, b.Slug)
+}
+
 // RenderEntry renders the HTML for a dictionary entry.
 func RenderEntry(entry Entry) string {
 	return fmt.Sprintf(
 		`<h2>%s</h2><div>%s</div>`,
 		entry.DisplayTitle,
-		entry.Content,
+		addSenseFragments(addSenseAnchors(entry.Content, entry.Senses)),
 	)
 }
 
@@ -134,6 +212,107 @@ func GetAdjacentEntrySlugs(slug string) (string, string) {
 	return prev, next
 }
 
+// GetEntryData returns the structured representation of the entry with the given slug.
+func GetEntryData(slug string) (EntryData, bool) {
+	i, ok := entryIndexBySlug[slug]
+	if !ok {
+		return EntryData{}, false
+	}
+
+	entry := AllEntries[i]
+	prevSlug, nextSlug := GetAdjacentEntrySlugs(slug)
+	data := EntryData{
+		Slug:            entry.Slug,
+		DisplayTitle:    entry.DisplayTitle,
+		NormalizedTitle: entry.NormalizedTitle,
+		URL:             EntryPath(entry.Slug),
+		PrevSlug:        prevSlug,
+		NextSlug:        nextSlug,
+		HTML:            entry.Content,
+		Notes:           entry.Notes,
+		Senses:          []SenseData{},
+	}
+	for _, sense := range entry.Senses {
+		senseData := SenseData{
+			Number:       sense.Number,
+			Block:        sense.Block,
+			PartOfSpeech: sense.PartOfSpeech,
+			Form:         sense.Form,
+			Registers:    sense.Registers,
+			Gloss:        sense.Gloss,
+			Synonyms:     sense.Synonyms,
+			Anchor:       sense.Anchor(),
+			HeaderHTML:   sense.HeaderHTML,
+			Subsections:  []SubsectionData{},
+		}
+		for _, subsection := range sense.Subsections {
+			senseData.Subsections = append(senseData.Subsections, SubsectionData{
+				Letter:     subsection.Letter,
+				Title:      subsection.Title,
+				Anchor:     sense.Anchor() + subsection.Letter,
+				Paragraphs: subsection.Paragraphs,
+			})
+		}
+		data.Senses = append(data.Senses, senseData)
+	}
+
+	return data, true
+}
+
+// GetSense returns the sense of an entry with the given number, such as "1" in "donar 1d".
+// When the entry has several grammatical blocks, partOfSpeech (e.g. "v. intr.") selects
+// the block; if it is empty or does not match, the first sense with that number is returned.
+func GetSense(slug, partOfSpeech, number string) (Sense, bool) {
+	i, ok := entryIndexBySlug[slug]
+	if !ok || number == "" {
+		return Sense{}, false
+	}
+
+	var found Sense
+	ok = false
+	for _, sense := range AllEntries[i].Senses {
+		if sense.Number != number {
+			continue
+		}
+		if partOfSpeech != "" && strings.Contains(sense.PartOfSpeech, partOfSpeech) {
+			return sense, true
+		}
+		if !ok {
+			found, ok = sense, true
+		}
+	}
+
+	return found, ok
+}
+
+// splitSenseRef splits a sense reference such as "1d" into the sense number and
+// the subsection letter.
+func splitSenseRef(ref string) (string, string) {
+	i := strings.IndexFunc(ref, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		return ref, ""
+	}
+	return ref[:i], strings.TrimSpace(ref[i:])
+}
+
+// renderSenseExcerpt renders the header of a sense and, if letter is not empty,
+// the subsection with that letter.
+func renderSenseExcerpt(sense Sense, letter string) string {
+	var b strings.Builder
+	b.WriteString("<p>" + sense.HeaderHTML + "</p>")
+	for _, subsection := range sense.Subsections {
+		if letter == "" || subsection.Letter != letter {
+			continue
+		}
+		fmt.Fprintf(&b, `<div class="indented-content"><p><em>%s</em>) <span class="smallcaps">%s</span></p>`, subsection.Letter, html.EscapeString(subsection.Title))
+		for _, p := range subsection.Paragraphs {
+			b.WriteString("<p>" + p + "</p>")
+		}
+		b.WriteString("</div>")
+	}
+	return b.String()
+}
+
 // GetNavigationLetters returns the previous and next letters in the Catalan alphabet.
 // Returns empty strings for prev/next if at the beginning/end of the alphabet.
 func GetNavigationLetters(letter string) (string, string) {
@@ -171,18 +350,82 @@ func CreateStaticPageData(path, title string) PageData {
 
 	if path == "glossari" {
 		data.GlossaryLetters = slices.Sorted(maps.Keys(Glossary))
-		data.GlossaryContent = Glossary
+		data.GlossaryContent = make(map[string]template.HTML, len(Glossary))
+		for letter, content := range Glossary {
+			data.GlossaryContent[letter] = template.HTML(addSenseFragments(string(content)))
+		}
 	}
 
 	return data
 }
 
+// CreateGlossaryTermPageData creates a fully populated PageData struct for a glossary term page.
+// The terms sharing the given id are shown with the senses referenced by their targets.
+func CreateGlossaryTermPageData(terms []GlossaryTerm) PageData {
+	data := PageData{
+		PlainTextTitle: terms[0].Term,
+		PageType:       "glossary-term",
+		Letter:         terms[0].Letter,
+	}
+	for _, term := range terms {
+		data.ExpandedGlossaryTerms = append(data.ExpandedGlossaryTerms, expandGlossaryTerm(term))
+	}
+
+	return data
+}
+
+// CreateGlossaryLetterPageData creates a fully populated PageData struct for a glossary letter page.
+func CreateGlossaryLetterPageData(letter string, terms []GlossaryTerm, prevLetter, nextLetter string) PageData {
+	return PageData{
+		PlainTextTitle:  fmt.Sprintf("Glossari: termes que comencen per %s", letter),
+		PageType:        "glossary-letter",
+		Letter:          letter,
+		PrevLetter:      prevLetter,
+		NextLetter:      nextLetter,
+		GlossaryLetters: slices.Sorted(maps.Keys(Glossary)),
+		GlossaryTerms:   terms,
+	}
+}
+
+// CreateIdiomsPageData creates a fully populated PageData struct for the idioms index page.
+func CreateIdiomsPageData() PageData {
+	return PageData{
+		PlainTextTitle: "Modismes i fraseologia",
+		PageType:       "idioms",
+		IdiomLetters:   GetIdiomLetters(),
+	}
+}
+
+// CreateIdiomLetterPageData creates a fully populated PageData struct for an idioms letter page.
+func CreateIdiomLetterPageData(letter string, idioms []Idiom, prevLetter, nextLetter string) PageData {
+	return PageData{
+		PlainTextTitle: fmt.Sprintf("Modismes que comencen per %s", letter),
+		PageType:       "idioms-letter",
+		Letter:         letter,
+		PrevLetter:     prevLetter,
+		NextLetter:     nextLetter,
+		IdiomLetters:   GetIdiomLetters(),
+		Idioms:         idioms,
+	}
+}
+
+// CreateIdiomsByEntryPageData creates a fully populated PageData struct for the page
+// listing the idioms grouped by the entry they are filed under.
+func CreateIdiomsByEntryPageData() PageData {
+	return PageData{
+		PlainTextTitle: "Modismes per lema",
+		PageType:       "idioms-by-entry",
+		IdiomLetters:   GetIdiomLetters(),
+		IdiomGroups:    GetIdiomsByEntry(),
+	}
+}
+
 // CreateSemanticFieldPageData creates a fully populated PageData struct for a semantic field page.
 func CreateSemanticFieldPageData(title, body string) PageData {
 	return PageData{
 		PlainTextTitle: title,
 		PageType:       "semantic-field",
-		ContentHTML:    template.HTML(body),
+		ContentHTML:    template.HTML(addSenseFragments(body)),
 	}
 }
 
@@ -203,6 +446,9 @@ func CreateLetterPageData(letter string, entries []LetterEntry, prevLetter, next
 //   - slug: The lema's unique identifier (e.g., "absència", "adonar-se_(de)")
 //   - entryHTML: The rendered HTML content for the lema
 //   - prevSlug, nextSlug: Slugs for navigation to adjacent entries
+//
+// The pages linking to the entry are looked up from the link graph, and its
+// lexical relations are grouped by kind.
 func CreateEntryPageData(slug, entryHTML, prevSlug, nextSlug string) PageData {
 	return PageData{
 		PlainTextTitle: strings.ReplaceAll(slug, "_", " "),
@@ -210,6 +456,8 @@ func CreateEntryPageData(slug, entryHTML, prevSlug, nextSlug string) PageData {
 		ContentHTML:    template.HTML(entryHTML),
 		PrevSlug:       prevSlug,
 		NextSlug:       nextSlug,
+		Backlinks:      GetBacklinks(slug),
+		Relations:      groupRelations(GetEntryRelations(slug)),
 	}
 }
 
@@ -221,15 +469,112 @@ func Create404PageData() PageData {
 	}
 }
 
+// CreateEntryNotFoundPageData creates page data for the 404 page of a missing entry,
+// suggesting the entries with the closest titles (see SuggestEntries).
+func CreateEntryNotFoundPageData(slug string) PageData {
+	pageData := Create404PageData()
+	pageData.Suggestions = SuggestEntries(slug)
+	return pageData
+}
+
+// CreateSynonymPageData creates page data for the page of a word listed as a
+// synonym or related word in the given senses, grouped by kind.
+func CreateSynonymPageData(sources []SynonymSource) PageData {
+	word := sources[0].Word
+	pageData := PageData{
+		PlainTextTitle: "Lemes que inclouen " + word,
+		PageType:       "synonym",
+		Word:           word,
+		SynonymGroups:  groupSynonymSources(sources),
+	}
+	if matches := SearchTitles(word); len(matches) > 0 && matches[0].Match == MatchExact {
+		pageData.WordEntrySlug = matches[0].Slug
+	}
+	return pageData
+}
+
+// CreateSearchPageData creates page data for the full-text search page.
+// The results are those of the given page, out of total results.
+func CreateSearchPageData(query string, results []TextSearchResult, total, page, prevPage, nextPage int) PageData {
+	title := "Cerca"
+	if query != "" {
+		title = "Cerca: " + query
+	}
+	return PageData{
+		PlainTextTitle: title,
+		PageType:       "search",
+		Query:          query,
+		TitleMatches:   ExactTitleMatches(query),
+		SearchResults:  results,
+		SearchTotal:    total,
+		Page:           page,
+		PrevPage:       prevPage,
+		NextPage:       nextPage,
+	}
+}
+
+// CreateSuffixSearchPageData creates page data for the search page of the words
+// ending with a suffix pattern. The results are those of the given page, out of total results.
+func CreateSuffixSearchPageData(pattern string, results []WordMatch, total, page, prevPage, nextPage int) PageData {
+	return PageData{
+		PlainTextTitle: "Paraules acabades en " + pattern,
+		PageType:       "search",
+		Suffix:         pattern,
+		SuffixResults:  results,
+		SearchTotal:    total,
+		Page:           page,
+		PrevPage:       prevPage,
+		NextPage:       nextPage,
+	}
+}
+
+// CreateSuffixSearchErrorPageData creates page data for the search page of a suffix
+// pattern over the limits of SearchSuffix, explaining the limits.
+func CreateSuffixSearchErrorPageData(pattern string) PageData {
+	pageData := CreateSuffixSearchPageData(pattern, nil, 0, 1, 0, 0)
+	pageData.SearchError = fmt.Sprintf("La terminació pot tenir com a màxim %d caràcters i %d comodins («?» o «*»).", MaxSuffixPatternLength, MaxSuffixWildcards)
+	return pageData
+}
+
+// CreateStaticSearchPageData creates page data for the full-text search page of the
+// static site, where js/search-text.js searches the static search index.
+func CreateStaticSearchPageData() PageData {
+	pageData := CreateSearchPageData("", nil, 0, 1, 0, 0)
+	pageData.StaticSearch = true
+	return pageData
+}
+
+// CreateRedirectPageData creates page data for a page of the static site redirecting
+// to an entry chosen by the given script, for the redirects served by the server.
+func CreateRedirectPageData(title, script string) PageData {
+	return PageData{
+		PlainTextTitle: title,
+		PageType:       "redirect",
+		RedirectScript: script,
+	}
+}
+
+// GetSearchData returns the data used by the entries search, in dictionary order.
+func GetSearchData() []SearchItem {
+	items := make([]SearchItem, 0, len(AllEntries))
+	for _, entry := range AllEntries {
+		items = append(items, SearchItem{
+			Slug:    entry.Slug,
+			Display: entry.DisplayTitle,
+			Search:  entry.NormalizedTitle,
+		})
+	}
+	return items
+}
+
 // GetEntriesByFirstLetter returns entry data for lemes starting with the given letter.
 // The letter parameter should be a single lowercase letter (a-z).
 // Returns an empty slice if no entries are found for the given letter.
-// Entries are assumed to be pre-sorted in Catalan locale order from the data export.
-// Normalized titles are assumed to be converted in the export (lowercase, removed accents).
+// Entries are sorted in Catalan collation order at load time.
 func GetEntriesByFirstLetter(letter string) []LetterEntry {
 	var entries []LetterEntry
 	for _, entry := range AllEntries {
-		if len(entry.NormalizedTitle) > 0 && entry.NormalizedTitle[0] == letter[0] {
+		if catalan.FirstLetter(entry.NormalizedTitle) == letter {
 			entries = append(entries, LetterEntry{
 				Slug:         entry.Slug,
 				DisplayTitle: template.HTML(entry.DisplayTitle),
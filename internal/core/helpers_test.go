@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGetEntryData(t *testing.T) {
+	loadTestData(t)
+
+	// Senses of several grammatical blocks, with the anchors of the entry page.
+	data, ok := GetEntryData("vent")
+	if !ok {
+		t.Fatal("GetEntryData(vent) not found")
+	}
+	got, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"slug": "vent",
+		"title": "vent",
+		"normalized_title": "vent",
+		"url": "/lema/vent",
+		"prev_slug": "trist_|_trista",
+		"html": "<p>m.</p><p><strong>1</strong>. [cat. val.] aire</p><div class=\"indented-content\"><p><em>e</em>) <span class=\"smallcaps\">Etimologia</span></p><p>Del llatí <em>ventus</em>.</p></div><hr><p>v. intr.</p><p><strong>1</strong>. bufar</p>",
+		"senses": [
+			{
+				"number": "1",
+				"block": 0,
+				"part_of_speech": "m.",
+				"registers": ["cat. val."],
+				"synonyms": ["aire"],
+				"anchor": "1",
+				"header_html": "<strong>1</strong>. [cat. val.] aire",
+				"subsections": [
+					{"letter": "e", "title": "Etimologia", "anchor": "1e", "paragraphs": ["Del llatí <em>ventus</em>."]}
+				]
+			},
+			{
+				"number": "1",
+				"block": 1,
+				"part_of_speech": "v. intr.",
+				"synonyms": ["bufar"],
+				"anchor": "2-1",
+				"header_html": "<strong>1</strong>. bufar",
+				"subsections": []
+			}
+		]
+	}`
+	var gotJSON, wantJSON any
+	if err := json.Unmarshal(got, &gotJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotJSON, wantJSON) {
+		t.Errorf("GetEntryData(vent) =\n%s\nwant\n%s", got, want)
+	}
+
+	// Previous and next entries, in dictionary order.
+	tests := []struct {
+		slug, prev, next string
+		anchors          []string // Anchors of the senses and subsections
+	}{
+		{"abril", "", "aire", []string{"1", "1a", "1d", "2", "2a", "2c"}},
+		{"por", "aire", "trist_|_trista", []string{"5", "5a", "5", "5c", "5d"}},
+		{"trist_|_trista", "por", "vent", []string{"1"}},
+	}
+	for _, tt := range tests {
+		data, ok := GetEntryData(tt.slug)
+		if !ok {
+			t.Errorf("GetEntryData(%q) not found", tt.slug)
+			continue
+		}
+		if data.PrevSlug != tt.prev || data.NextSlug != tt.next {
+			t.Errorf("GetEntryData(%q) prev, next = %q, %q; want %q, %q", tt.slug, data.PrevSlug, data.NextSlug, tt.prev, tt.next)
+		}
+		var anchors []string
+		for _, sense := range data.Senses {
+			anchors = append(anchors, sense.Anchor)
+			for _, subsection := range sense.Subsections {
+				anchors = append(anchors, subsection.Anchor)
+			}
+		}
+		if !reflect.DeepEqual(anchors, tt.anchors) {
+			t.Errorf("GetEntryData(%q) anchors = %v; want %v", tt.slug, anchors, tt.anchors)
+		}
+	}
+
+	for _, slug := range []string{"", "xyz", "Abril", "abril/"} {
+		if _, ok := GetEntryData(slug); ok {
+			t.Errorf("GetEntryData(%q) found", slug)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package core
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// idiomsSubsection is the letter of the "Modismes i fraseologia" subsection.
+const idiomsSubsection = "d"
+
+var (
+	idiomHeadStartPattern = regexp.MustCompile(`^\s*<strong>`)
+	idiomBoldPattern      = regexp.MustCompile(`^\s*<strong>(.*?)</strong>`)
+	// idiomConnectorPattern matches the text joining the bold forms of an idiom,
+	// e.g. " o " in "<strong>llaç escorredor</strong> o <strong>baga escorredora</strong>"
+	// or "(o" in "<strong>ser un tros de carn batejada </strong>(o<strong> ...</strong>)".
+	idiomConnectorPattern   = regexp.MustCompile(`^[\s()]*(?:o\b[\s(]*)?<strong>`)
+	idiomAlternativePattern = regexp.MustCompile(`\(o\s+([^()]*)\)`)
+	idiomCloseParenPattern  = regexp.MustCompile(`^\s*\)`)
+	idiomExamplePattern     = regexp.MustCompile(`\s*\bEx\.\s*:?\s*`)
+)
+
+// Idiom represents an expression of a "Modismes i fraseologia" subsection,
+// e.g. "A l'abril, cada gota en val mil", with its explanation.
+type Idiom struct {
+	// Expression is the expression as written in bold, including its
+	// alternative forms (e.g. "llaç escorredor o baga escorredora").
+	Expression string
+
+	// Forms are the alternative forms of the expression
+	// (e.g. "llaç escorredor", "baga escorredora").
+	Forms []string
+
+	// Gloss is the explanation of the expression, in plain text.
+	Gloss string
+
+	// Examples are the usage examples of the expression, in plain text.
+	Examples []string
+
+	// Letter is the uppercase letter the expression is listed under.
+	Letter string
+
+	// Slug is the slug of the entry the idiom is filed under.
+	Slug string
+
+	// PartOfSpeech and Sense identify the sense the idiom is filed under.
+	PartOfSpeech string
+	Sense        string
+
+	// Anchor is the id of the subsection holding the idiom in the entry page.
+	Anchor string
+
+	// senseIndex is the index of the sense in the senses of the entry, as sense
+	// numbers may be repeated in the data.
+	senseIndex int
+}
+
+// IdiomSearchItem is an idiom in the data used by the idioms search.
+// Keys are kept short as in the entries search data (see SearchItem).
+type IdiomSearchItem struct {
+	Display string `json:"d"` // Expression and entry title, as HTML
+	Search  string `json:"s"` // Normalized forms of the expression
+	Slug    string `json:"t"`
+	Anchor  string `json:"a"`
+}
+
+// IdiomGroup groups the idioms filed under the same entry.
+type IdiomGroup struct {
+	Slug         string
+	DisplayTitle template.HTML
+	Idioms       []Idiom
+}
+
+// parseIdioms extracts the idioms of an entry from its "Modismes i fraseologia" subsections.
+func parseIdioms(entry Entry) []Idiom {
+	var idioms []Idiom
+	for i, sense := range entry.Senses {
+		for _, subsection := range sense.Subsections {
+			if subsection.Letter != idiomsSubsection {
+				continue
+			}
+			for _, p := range subsection.Paragraphs {
+				idiom, ok := parseIdiom(p)
+				if !ok {
+					continue
+				}
+				idiom.Slug = entry.Slug
+				idiom.PartOfSpeech = sense.PartOfSpeech
+				idiom.Sense = sense.Number
+				idiom.Anchor = sense.Anchor() + subsection.Letter
+				idiom.senseIndex = i
+				idioms = append(idioms, idiom)
+			}
+		}
+	}
+	return idioms
+}
+
+// parseIdiom parses an idiom paragraph such as
+// `<strong>de bell antuvi </strong>[lit.] Abans que res, en primer lloc. Ex.: <em>...</em>`.
+// The expression is the sequence of bold forms at the start of the paragraph.
+// It reports false if the paragraph does not start with an expression.
+func parseIdiom(p string) (Idiom, bool) {
+	if !idiomHeadStartPattern.MatchString(p) {
+		return Idiom{}, false
+	}
+
+	// Bold parts joined by " o " outside the bold text and outside parentheses are
+	// alternative forms; other connectors, such as parentheses, belong to the current form.
+	var forms []string
+	var form strings.Builder
+	end := 0
+	for {
+		m := idiomBoldPattern.FindStringSubmatchIndex(p[end:])
+		if m == nil {
+			break
+		}
+		form.WriteString(p[end+m[2] : end+m[3]])
+		end += m[1]
+
+		connector := idiomConnectorPattern.FindString(p[end:])
+		if connector == "" {
+			break
+		}
+		connector = strings.TrimSuffix(connector, "<strong>")
+		head := plainText(p[:end])
+		if strings.TrimSpace(connector) == "o" && strings.Count(head, "(") == strings.Count(head, ")") {
+			forms = append(forms, form.String())
+			form.Reset()
+		} else {
+			form.WriteString(connector)
+		}
+		end += len(connector)
+	}
+	if head := plainText(p[:end]); strings.Count(head, "(") > strings.Count(head, ")") {
+		if m := idiomCloseParenPattern.FindStringIndex(p[end:]); m != nil {
+			end += m[1]
+			form.WriteString(")")
+		}
+	}
+	forms = append(forms, form.String())
+
+	idiom := Idiom{Expression: strings.TrimRight(plainText(p[:end]), " .:,")}
+	if len([]rune(idiom.Expression)) < 2 {
+		return Idiom{}, false
+	}
+	idiom.Letter = idiomLetter(idiom.Expression)
+	for _, form := range forms {
+		idiom.Forms = append(idiom.Forms, idiomForms(form)...)
+	}
+
+	gloss, examples := plainText(p[end:]), ""
+	if loc := idiomExamplePattern.FindStringIndex(gloss); loc != nil {
+		gloss, examples = gloss[:loc[0]], gloss[loc[1]:]
+	}
+	idiom.Gloss = strings.TrimSpace(strings.TrimLeft(gloss, " .:"))
+	for _, example := range strings.Split(examples, " / ") {
+		if example = strings.TrimSpace(example); example != "" {
+			idiom.Examples = append(idiom.Examples, example)
+		}
+	}
+
+	return idiom, true
+}
+
+// idiomForms returns the forms written in the HTML of a part of an idiom,
+// expanding the parenthesized alternatives starting with "o", e.g.
+// "alçar (o arrencar) el bull" gives "alçar el bull" and "arrencar el bull".
+func idiomForms(form string) []string {
+	form = strings.NewReplacer("( ", "(", " )", ")").Replace(plainText(form))
+	form = strings.TrimRight(form, " .:,")
+	if form == "" {
+		return nil
+	}
+
+	loc := idiomAlternativePattern.FindStringSubmatchIndex(form)
+	if loc == nil {
+		return []string{form}
+	}
+	prefix := strings.Fields(form[:loc[0]])
+	alternative := strings.Fields(form[loc[2]:loc[3]])
+	suffix := strings.TrimSpace(form[loc[1]:])
+
+	// The alternative replaces the end of the text before it, starting at its first
+	// word if it is repeated (e.g. "tenir el cap ple de fum (o de pardals)"), or the
+	// same number of words otherwise (e.g. "caure de l'ase (o del burro)").
+	start := slices.Index(prefix, alternative[0])
+	if start < 0 {
+		start = len(prefix) - max(1, min(len(alternative), len(prefix)-1))
+	}
+	replaced := append(slices.Clone(prefix[:max(start, 0)]), alternative...)
+
+	var forms []string
+	for _, words := range [][]string{prefix, replaced} {
+		forms = append(forms, idiomForms(strings.TrimSpace(strings.Join(words, " ")+" "+suffix))...)
+	}
+	return forms
+}
+
+// idiomLetter returns the uppercase letter an expression is listed under,
+// ignoring leading punctuation and accents.
+func idiomLetter(expression string) string {
+	return strings.ToUpper(catalan.FirstLetter(expression))
+}
+
+// sortIdioms sorts idioms alphabetically by expression.
+func sortIdioms(idioms []Idiom) {
+	slices.SortStableFunc(idioms, func(a, b Idiom) int {
+		return catalan.Compare(a.Expression, b.Expression)
+	})
+}
+
+// GetIdiomLetters returns the uppercase letters idioms are listed under.
+func GetIdiomLetters() []string {
+	var letters []string
+	for _, idiom := range Idioms {
+		if !slices.Contains(letters, idiom.Letter) {
+			letters = append(letters, idiom.Letter)
+		}
+	}
+	slices.Sort(letters)
+	return letters
+}
+
+// GetIdiomsByLetter returns the idioms listed under the given uppercase letter,
+// sorted alphabetically.
+func GetIdiomsByLetter(letter string) []Idiom {
+	var idioms []Idiom
+	for _, idiom := range Idioms {
+		if idiom.Letter == letter {
+			idioms = append(idioms, idiom)
+		}
+	}
+	sortIdioms(idioms)
+	return idioms
+}
+
+// GetIdiomNavigationLetters returns the previous and next idiom letters.
+// Returns empty strings for prev/next if at the beginning/end of the list.
+func GetIdiomNavigationLetters(letter string) (string, string) {
+	letters := GetIdiomLetters()
+	i := slices.Index(letters, letter)
+	if i < 0 {
+		return "", ""
+	}
+
+	var prev, next string
+	if i > 0 {
+		prev = letters[i-1]
+	}
+	if i < len(letters)-1 {
+		next = letters[i+1]
+	}
+
+	return prev, next
+}
+
+// GetIdiomsByEntry returns the idioms grouped by the entry they are filed under,
+// in dictionary order.
+func GetIdiomsByEntry() []IdiomGroup {
+	var groups []IdiomGroup
+	for _, idiom := range Idioms {
+		if len(groups) == 0 || groups[len(groups)-1].Slug != idiom.Slug {
+			groups = append(groups, IdiomGroup{
+				Slug:         idiom.Slug,
+				DisplayTitle: template.HTML(AllEntries[entryIndexBySlug[idiom.Slug]].DisplayTitle),
+			})
+		}
+		group := &groups[len(groups)-1]
+		group.Idioms = append(group.Idioms, idiom)
+	}
+	return groups
+}
+
+// GetIdiomSearchData returns the data used by the idioms search, sorted alphabetically.
+func GetIdiomSearchData() []IdiomSearchItem {
+	idioms := slices.Clone(Idioms)
+	sortIdioms(idioms)
+
+	items := make([]IdiomSearchItem, 0, len(idioms))
+	for _, idiom := range idioms {
+		title := AllEntries[entryIndexBySlug[idiom.Slug]].DisplayTitle
+		items = append(items, IdiomSearchItem{
+			Display: html.EscapeString(idiom.Expression) + ` <span class="no-bold">(` + title + `)</span>`,
+			Search:  catalan.Normalize(strings.Join(idiom.Forms, " / ")),
+			Slug:    idiom.Slug,
+			Anchor:  idiom.Anchor,
+		})
+	}
+	return items
+}
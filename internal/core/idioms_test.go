@@ -0,0 +1,74 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdiomForms(t *testing.T) {
+	tests := []struct {
+		form string
+		want []string
+	}{
+		{"de bell antuvi ", []string{"de bell antuvi"}},
+		{"alçar (o arrencar) el bull", []string{"alçar el bull", "arrencar el bull"}},
+		{"tenir el cap ple de fum (o de pardals)", []string{"tenir el cap ple de fum", "tenir el cap ple de pardals"}},
+		{"caure de l'ase (o del burro)", []string{"caure de l'ase", "caure del burro"}},
+		{"fer <em>(o</em> dir) alguna cosa", []string{"fer alguna cosa", "dir alguna cosa"}},
+		{"fer (o ) alguna cosa", []string{"fer (o) alguna cosa"}},
+		{" .", nil},
+	}
+	for _, tt := range tests {
+		if got := idiomForms(tt.form); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("idiomForms(%q) = %q; want %q", tt.form, got, tt.want)
+		}
+	}
+}
+
+func TestParseIdiom(t *testing.T) {
+	tests := []struct {
+		p    string
+		want Idiom
+		ok   bool
+	}{
+		{
+			`<strong>de bell antuvi </strong>[lit.] Abans que res. Ex.: <em>Ho va dir de bell antuvi.</em> / <em>Altre.</em>`,
+			Idiom{
+				Expression: "de bell antuvi",
+				Forms:      []string{"de bell antuvi"},
+				Gloss:      "[lit.] Abans que res.",
+				Examples:   []string{"Ho va dir de bell antuvi.", "Altre."},
+				Letter:     "D",
+			},
+			true,
+		},
+		{
+			`<strong>llaç escorredor</strong> o <strong>baga escorredora</strong>: Nus que s'estreny.`,
+			Idiom{
+				Expression: "llaç escorredor o baga escorredora",
+				Forms:      []string{"llaç escorredor", "baga escorredora"},
+				Gloss:      "Nus que s'estreny.",
+				Letter:     "L",
+			},
+			true,
+		},
+		{
+			`<strong>ser un tros de carn batejada </strong>(o<strong> de pa beneit</strong>) Ser molt ximple.`,
+			Idiom{
+				Expression: "ser un tros de carn batejada (o de pa beneit)",
+				Forms:      []string{"ser un tros de carn batejada", "ser un tros de pa beneit"},
+				Gloss:      "Ser molt ximple.",
+				Letter:     "S",
+			},
+			true,
+		},
+		{`Text sense expressió.`, Idiom{}, false},
+		{`<strong>a</strong> b`, Idiom{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseIdiom(tt.p)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseIdiom(%q) =\n%+v, %v; want\n%+v, %v", tt.p, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package core
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Link source and target types.
+const (
+	LinkTypeEntry         = "entry"
+	LinkTypeGlossary      = "glossary"
+	LinkTypeSemanticField = "semantic-field"
+)
+
+var (
+	linkPattern     = regexp.MustCompile(`<a\s[^>]*?href="([^"]*)"[^>]*>(.*?)</a>`)
+	senseRefPattern = regexp.MustCompile(`^(\d+)(?:\s?\.?\s?([a-f])\b)?`)
+	refWordPattern  = regexp.MustCompile(`^\(?\p{L}{1,6}\.?\)?$`)
+)
+
+// Link represents a link to a dictionary page found in the content of another page.
+type Link struct {
+	// SourceType is the type of page containing the link (LinkTypeEntry,
+	// LinkTypeGlossary or LinkTypeSemanticField).
+	SourceType string
+
+	// SourceID identifies the page containing the link: the entry slug, the
+	// glossary term id, or the semantic field path.
+	SourceID string
+
+	// SourceTitle is the title of the page containing the link. It may include HTML.
+	SourceTitle string
+
+	// Href is the raw href attribute, as exported by the CMS.
+	Href string
+
+	// TargetType is the type of linked page (LinkTypeEntry or LinkTypeSemanticField).
+	TargetType string
+
+	// Target is the decoded slug (or path) of the linked page.
+	Target string
+
+	// PartOfSpeech is the grammatical category written after the link, if any (e.g. "v. tr.").
+	PartOfSpeech string
+
+	// Sense is the sense reference written after the link, if any (e.g. "1", "1d").
+	Sense string
+
+	// Resolved reports whether the target exists in the loaded data.
+	Resolved bool
+}
+
+// SourcePath returns the URL path of the page containing the link.
+func (l Link) SourcePath() string {
+	switch l.SourceType {
+	case LinkTypeGlossary:
+		return "/glossari#" + l.SourceID
+	case LinkTypeSemanticField:
+		return "/camp-semantic/" + l.SourceID
+	default:
+		return "/lema/" + l.SourceID
+	}
+}
+
+// buildLinks extracts the links to dictionary pages from entries, glossary and
+// semantic fields. It must be called after entryIndexBySlug and GlossaryTerms are built.
+func buildLinks() []Link {
+	var links []Link
+	for _, entry := range AllEntries {
+		links = appendLinks(links, LinkTypeEntry, entry.Slug, entry.DisplayTitle, entry.Content)
+	}
+	for _, term := range GlossaryTerms {
+		links = appendLinks(links, LinkTypeGlossary, term.ID, html.EscapeString(term.Term), string(term.HTML))
+	}
+	for _, field := range SemanticFields {
+		links = appendLinks(links, LinkTypeSemanticField, field.Path, html.EscapeString(field.Title), field.Body)
+	}
+	return links
+}
+
+// appendLinks appends the links found in an HTML fragment to the list.
+// Hrefs that are neither internal paths nor valid external URLs (e.g. "https://tombar",
+// a lema typed as a URL in the CMS) are considered broken entry links.
+func appendLinks(links []Link, sourceType, sourceID, sourceTitle, content string) []Link {
+	matches := linkPattern.FindAllStringSubmatchIndex(content, -1)
+	for i, m := range matches {
+		href := content[m[2]:m[3]]
+		link := Link{
+			SourceType:  sourceType,
+			SourceID:    sourceID,
+			SourceTitle: sourceTitle,
+			Href:        href,
+		}
+
+		switch {
+		case strings.HasPrefix(href, "/lema/"):
+			link.TargetType = LinkTypeEntry
+			link.Target, link.Resolved = resolveEntrySlug(strings.TrimPrefix(href, "/lema/"))
+		case strings.HasPrefix(href, "/camp-semantic/"):
+			link.TargetType = LinkTypeSemanticField
+			link.Target = strings.TrimPrefix(href, "/camp-semantic/")
+			link.Resolved = semanticFieldExists(link.Target)
+		default:
+			u, err := url.Parse(href)
+			if (err == nil && strings.Contains(u.Host, ".")) || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "mailto:") {
+				continue
+			}
+			link.TargetType = LinkTypeEntry
+			link.Target = strings.TrimPrefix(strings.TrimPrefix(href, "https://"), "http://")
+		}
+
+		link.PartOfSpeech, link.Sense = parseSenseRef(linkTail(content, matches, i))
+
+		links = append(links, link)
+	}
+
+	return links
+}
+
+// linkTail returns the HTML written after the i-th link match, up to the next link
+// or the end of the paragraph. It holds the sense reference, e.g. "donar</a> 1d".
+func linkTail(content string, matches [][]int, i int) string {
+	tailEnd := len(content)
+	if i+1 < len(matches) {
+		tailEnd = matches[i+1][0]
+	}
+	tail := content[matches[i][1]:tailEnd]
+	if end := strings.Index(tail, "</p>"); end >= 0 {
+		tail = tail[:end]
+	}
+	return tail
+}
+
+// addSenseFragments adds the anchor of the referenced sense to the entry links
+// followed by a sense reference, so that `<a href="/lema/donar">donar</a> 1d`
+// links to /lema/donar#1d. Links to senses that do not exist are left unchanged.
+func addSenseFragments(content string) string {
+	matches := linkPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	last := 0
+	for i, m := range matches {
+		href := content[m[2]:m[3]]
+		if !strings.HasPrefix(href, "/lema/") || strings.Contains(href, "#") {
+			continue
+		}
+		slug, ok := resolveEntrySlug(strings.TrimPrefix(href, "/lema/"))
+		if !ok {
+			continue
+		}
+		partOfSpeech, sense := parseSenseRef(linkTail(content, matches, i))
+		anchor := senseAnchor(slug, partOfSpeech, sense)
+		if anchor == "" {
+			continue
+		}
+
+		b.WriteString(content[last:m[3]])
+		b.WriteString("#" + anchor)
+		last = m[3]
+	}
+	b.WriteString(content[last:])
+
+	return b.String()
+}
+
+// senseAnchor returns the anchor of the sense referenced by a sense reference such
+// as "1d" in an entry page, or an empty string if the sense does not exist.
+// The subsection letter is only kept if the sense has that subsection.
+func senseAnchor(slug, partOfSpeech, ref string) string {
+	number, letter := splitSenseRef(ref)
+	sense, ok := GetSense(slug, partOfSpeech, number)
+	if !ok {
+		return ""
+	}
+	for _, subsection := range sense.Subsections {
+		if letter != "" && subsection.Letter == letter {
+			return sense.Anchor() + letter
+		}
+	}
+	return sense.Anchor()
+}
+
+// parseSenseRef parses the sense reference written after a link, such as
+// " v. tr. 1c CS", "-se 2c" or " (f.) 1c". It returns the grammatical category
+// and the sense, which are empty if the text does not start with a reference.
+func parseSenseRef(tail string) (string, string) {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(tail, ""))
+	attached := text != "" && !unicode.IsSpace(rune(text[0]))
+
+	i := strings.IndexFunc(text, unicode.IsDigit)
+	if i < 0 {
+		return "", ""
+	}
+
+	var partOfSpeech []string
+	for j, word := range strings.Fields(text[:i]) {
+		if (j == 0 && attached) || strings.HasPrefix(word, "-") {
+			// Suffix or ending of the linked word, e.g. "reunir</a>(-se) 5", "estimat</a> -ada 1c".
+			continue
+		}
+		if !refWordPattern.MatchString(word) {
+			return "", ""
+		}
+		word = strings.Trim(word, "()")
+		if strings.HasSuffix(word, ".") || word == "pron" || word == "tr" || word == "intr" {
+			partOfSpeech = append(partOfSpeech, word)
+		}
+	}
+
+	m := senseRefPattern.FindStringSubmatch(text[i:])
+	if m == nil {
+		return "", ""
+	}
+	return strings.Join(partOfSpeech, " "), m[1] + m[2]
+}
+
+// resolveEntrySlug decodes the slug part of an entry link and reports whether it
+// matches an entry. Links are accepted both as escaped by Go templates and as
+// escaped by PHP urlencode in the CMS ("+" for spaces, encoded brackets and colons).
+func resolveEntrySlug(raw string) (string, bool) {
+	var candidates []string
+	if slug, err := url.PathUnescape(raw); err == nil {
+		candidates = append(candidates, slug)
+	}
+	if slug, err := url.QueryUnescape(raw); err == nil {
+		candidates = append(candidates, slug, strings.ReplaceAll(slug, " ", "_"))
+	}
+
+	for _, slug := range candidates {
+		if _, ok := entryIndexBySlug[slug]; ok {
+			return slug, true
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], false
+	}
+	return raw, false
+}
+
+// semanticFieldExists reports whether a semantic field with the given path exists.
+func semanticFieldExists(path string) bool {
+	for _, field := range SemanticFields {
+		if field.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBrokenLinks returns the links whose target does not exist in the loaded data.
+func GetBrokenLinks() []Link {
+	var broken []Link
+	for _, link := range Links {
+		if !link.Resolved {
+			broken = append(broken, link)
+		}
+	}
+	return broken
+}
+
+// buildBacklinks builds the reverse index of Links, mapping each entry slug to
+// the pages linking to it. Links from an entry to itself are ignored.
+func buildBacklinks() map[string]Backlinks {
+	backlinks := make(map[string]Backlinks)
+	for _, link := range Links {
+		if link.TargetType != LinkTypeEntry || !link.Resolved {
+			continue
+		}
+		if link.SourceType == LinkTypeEntry && link.SourceID == link.Target {
+			continue
+		}
+
+		b := backlinks[link.Target]
+		switch link.SourceType {
+		case LinkTypeGlossary:
+			b.GlossaryTerms = appendBacklink(b.GlossaryTerms, link)
+		case LinkTypeSemanticField:
+			b.SemanticFields = appendBacklink(b.SemanticFields, link)
+		default:
+			b.Entries = appendBacklink(b.Entries, link)
+		}
+		backlinks[link.Target] = b
+	}
+	return backlinks
+}
+
+// appendBacklink adds a link to a list of backlinks, merging the sense
+// references of links coming from the same page.
+func appendBacklink(backlinks []Backlink, link Link) []Backlink {
+	i := slices.IndexFunc(backlinks, func(b Backlink) bool { return b.ID == link.SourceID })
+	if i < 0 {
+		backlinks = append(backlinks, Backlink{
+			ID:    link.SourceID,
+			Title: template.HTML(link.SourceTitle),
+		})
+		i = len(backlinks) - 1
+	}
+	if link.Sense != "" && !slices.Contains(backlinks[i].Senses, link.Sense) {
+		backlinks[i].Senses = append(backlinks[i].Senses, link.Sense)
+	}
+	return backlinks
+}
+
+// GetBacklinks returns the pages linking to the entry with the given slug.
+func GetBacklinks(slug string) Backlinks {
+	return backlinksBySlug[slug]
+}
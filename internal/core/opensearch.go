@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+)
+
+// maxTitleSuggestions is the maximum number of titles suggested to browsers.
+const maxTitleSuggestions = 10
+
+// openSearchDescription is the OpenSearch 1.1 description document of the site.
+type openSearchDescription struct {
+	XMLName          xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName        string          `xml:"ShortName"`
+	Description      string          `xml:"Description"`
+	InputEncoding    string          `xml:"InputEncoding"`
+	Language         string          `xml:"Language"`
+	Image            openSearchImage `xml:"Image"`
+	URLs             []openSearchURL `xml:"Url"`
+	SyndicationRight string          `xml:"SyndicationRight"`
+}
+
+type openSearchImage struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Type   string `xml:"type,attr"`
+	URL    string `xml:",chardata"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Method   string `xml:"method,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// GetSiteURL returns the public URL of the site from the SITE_URL env variable,
+// without a trailing slash, e.g. "https://example.org". It is empty if not set.
+func GetSiteURL() string {
+	return strings.TrimRight(os.Getenv("SITE_URL"), "/")
+}
+
+// OpenSearchDescription returns the OpenSearch description document of the site,
+// which lets browsers add it as a search engine. Searches go to the full-text search
+// page; suggestionsTemplate is the URL template of the title suggestions, relative
+// to siteURL, with the "{searchTerms}" parameter.
+func OpenSearchDescription(siteURL, suggestionsTemplate string) ([]byte, error) {
+	description := openSearchDescription{
+		ShortName:     "DIRELEX",
+		Description:   "Diccionari de recursos lexicals",
+		InputEncoding: "UTF-8",
+		Language:      "ca",
+		Image: openSearchImage{
+			Width:  64,
+			Height: 64,
+			Type:   "image/svg+xml",
+			URL:    siteURL + "/favicon.svg",
+		},
+		URLs: []openSearchURL{
+			{Type: "text/html", Method: "get", Template: siteURL + "/cerca?q={searchTerms}"},
+			{Type: "application/x-suggestions+json", Method: "get", Template: siteURL + suggestionsTemplate},
+		},
+		SyndicationRight: "open",
+	}
+
+	content, err := xml.MarshalIndent(description, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// GetTitleSuggestions returns the response to an OpenSearch suggestions request:
+// the query and the plain text titles of the entries matching it (see SearchTitles).
+func GetTitleSuggestions(query string) []any {
+	titles := []string{}
+	for _, match := range SearchTitles(query) {
+		if len(titles) == maxTitleSuggestions {
+			break
+		}
+		titles = append(titles, plainText(string(match.DisplayTitle)))
+	}
+	return []any{query, titles}
+}
+
+// GetTitleSuggestionPrefixes returns the queries whose title suggestions are
+// prebuilt in the static site: the prefixes of the forms of all titles (see
+// titleForms), both normalized and as written, in lowercase.
+func GetTitleSuggestionPrefixes() []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, entry := range AllEntries {
+		written := strings.ToLower(plainText(entry.DisplayTitle))
+		for _, form := range append(titleForms(entry.NormalizedTitle), titleForms(written)...) {
+			runes := []rune(form)
+			for i := 1; i <= len(runes); i++ {
+				prefix := string(runes[:i])
+				if !seen[prefix] {
+					seen[prefix] = true
+					prefixes = append(prefixes, prefix)
+				}
+			}
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,370 @@
+package core
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// subsectionTitles maps subsection letters to their canonical titles.
+// The export is not fully consistent (extra spaces, capitalization, titles missing
+// when the header is inlined in the first paragraph), so titles are taken from here.
+var subsectionTitles = map[string]string{
+	"a": "Explicacions d'ús",
+	"b": "Usos inadequats o estilístics / Variants gràfiques i fonètiques",
+	"c": "Altres recursos lexicals",
+	"d": "Modismes i fraseologia",
+	"e": "Etimologia",
+	"f": "Explicacions suplementàries",
+}
+
+// registerMarkers contains the usage markers that may appear in brackets at the
+// start of a sense header, as listed in the abbreviations page.
+var registerMarkers = []string{
+	"abs.", "afec.", "ant.", "arc.", "augm.", "cult.", "dial.", "dim.", "espec.",
+	"eufem.", "fam.", "fig.", "improp.", "infant.", "intens.", "iròn.", "lit.",
+	"obs.", "pej.", "per ext.", "poc us.", "pop.", "sex.",
+	"cat. ebr.", "cat. eiv.", "cat. ins.", "cat. Mall.", "cat. Men.", "cat. nord-occ.",
+	"cat. nord-or.", "cat. occ.", "cat. or.", "cat. pir.", "cat. ros.", "cat. val.",
+}
+
+var (
+	tagPattern            = regexp.MustCompile(`<[^>]*>`)
+	attrPattern           = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+	senseHeaderPattern    = regexp.MustCompile(`^\s*(?:<strong>\s*)?\d+`)
+	senseNumberPattern    = regexp.MustCompile(`^(\d+)\s*\.?\s*`)
+	partOfSpeechPattern   = regexp.MustCompile(`^(adj|adv|conj|f|interj|loc|m|prep|pron|v)\b`)
+	subsectionHeadPattern = regexp.MustCompile(`^\s*(?:<em>\s*)?([a-f])\s*(?:</em>\s*)?\)\s*(?:</em>)?`)
+	smallcapsPattern      = regexp.MustCompile(`^\s*<span class="smallcaps">(.*?)</span>\s*`)
+)
+
+// htmlBlock is a top-level element of an HTML fragment.
+type htmlBlock struct {
+	Tag        string
+	Class      string
+	ID         string
+	Inner      string // Raw inner HTML
+	Start      int    // Offset of the opening tag in the fragment
+	InnerStart int    // Offset of the inner HTML in the fragment
+}
+
+// splitBlocks splits an HTML fragment into its top-level elements.
+// Text outside of elements is ignored. The content exported by the CMS is a flat
+// sequence of paragraphs, dividers and indented divs, so no full HTML parser is needed.
+func splitBlocks(s string) []htmlBlock {
+	var blocks []htmlBlock
+	var current htmlBlock
+	depth, innerStart := 0, 0
+
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			break
+		}
+		tag := s[i+1 : i+end]
+		next := i + end + 1
+		fields := strings.Fields(tag)
+		if len(fields) == 0 || strings.Trim(fields[0], "/") == "" {
+			// Tags without a name (e.g. "<>" or "</>" in malformed content) are skipped.
+			i = next
+			continue
+		}
+		name := strings.ToLower(strings.TrimRight(fields[0], "/"))
+
+		switch {
+		case strings.HasPrefix(tag, "/"):
+			depth--
+			if depth == 0 {
+				current.Inner = s[innerStart:i]
+				blocks = append(blocks, current)
+			}
+			if depth < 0 {
+				depth = 0
+			}
+		case isVoidElement(name) || strings.HasSuffix(tag, "/"):
+			if depth == 0 {
+				blocks = append(blocks, htmlBlock{Tag: name, Start: i, InnerStart: next})
+			}
+		default:
+			if depth == 0 {
+				current = htmlBlock{Tag: name, Start: i, InnerStart: next}
+				for _, attr := range attrPattern.FindAllStringSubmatch(tag, -1) {
+					switch attr[1] {
+					case "class":
+						current.Class = attr[2]
+					case "id":
+						current.ID = attr[2]
+					}
+				}
+				innerStart = next
+			}
+			depth++
+		}
+		i = next
+	}
+
+	return blocks
+}
+
+// isVoidElement reports whether the HTML element has no closing tag.
+func isVoidElement(name string) bool {
+	switch name {
+	case "br", "hr", "img", "input", "meta", "link", "wbr":
+		return true
+	default:
+		return false
+	}
+}
+
+// plainText converts an HTML fragment to plain text, decoding entities and
+// collapsing whitespace.
+func plainText(s string) string {
+	s = html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parseEntryContent parses the HTML content of an entry into its senses.
+// Paragraphs that do not belong to any sense (e.g. "Vegeu ..." references) are
+// returned as notes.
+func parseEntryContent(content string) ([]Sense, []string) {
+	var senses []Sense
+	var notes []string
+	var partOfSpeech, form string
+	block := 0
+
+	// appendParagraph adds a paragraph to the current sense, starting a new
+	// subsection when the paragraph is a subsection header. The offset is the
+	// position of the paragraph in the content, used to add anchors when rendering.
+	appendParagraph := func(p string, offset int) {
+		if len(senses) == 0 {
+			notes = append(notes, p)
+			return
+		}
+		sense := &senses[len(senses)-1]
+
+		m := subsectionHeadPattern.FindStringSubmatch(p)
+		if m != nil {
+			letter := m[1]
+			rest := p[len(m[0]):]
+			title := subsectionTitles[letter]
+			if sc := smallcapsPattern.FindStringSubmatch(rest); sc != nil {
+				if title == "" {
+					title = plainText(sc[1])
+				}
+				rest = rest[len(sc[0]):]
+			}
+			subsection := Subsection{Letter: letter, Title: title, offset: offset}
+			if plainText(rest) != "" {
+				subsection.Paragraphs = append(subsection.Paragraphs, strings.TrimSpace(rest))
+			}
+			sense.Subsections = append(sense.Subsections, subsection)
+			return
+		}
+
+		if len(sense.Subsections) == 0 {
+			sense.Subsections = append(sense.Subsections, Subsection{offset: -1})
+		}
+		last := &sense.Subsections[len(sense.Subsections)-1]
+		last.Paragraphs = append(last.Paragraphs, p)
+	}
+
+	for _, b := range splitBlocks(content) {
+		switch b.Tag {
+		case "hr":
+			// A divider starts a new grammatical block, which restarts sense numbering.
+			block++
+			partOfSpeech, form = "", ""
+		case "div":
+			for _, p := range splitBlocks(b.Inner) {
+				if p.Tag == "p" {
+					appendParagraph(p.Inner, b.InnerStart+p.Start)
+				}
+			}
+		case "p":
+			text := plainText(b.Inner)
+			switch {
+			case text == "":
+				continue
+			case senseHeaderPattern.MatchString(b.Inner):
+				sense := parseSenseHeader(text, block, partOfSpeech, form)
+				sense.HeaderHTML = b.Inner
+				sense.offset = b.Start
+				senses = append(senses, sense)
+			case partOfSpeechPattern.MatchString(text) && !hasSenseInBlock(senses, block):
+				partOfSpeech, form = parsePartOfSpeech(text)
+			case hasSenseInBlock(senses, block):
+				appendParagraph(b.Inner, b.Start)
+			default:
+				notes = append(notes, b.Inner)
+			}
+		}
+	}
+
+	return senses, notes
+}
+
+// addSenseAnchors adds the anchor ids of the senses and subsections to the header
+// paragraphs of the entry content, e.g. <p id="1"> and <p id="1d">, so that
+// individual senses can be linked. Headers that already have an id are left unchanged.
+func addSenseAnchors(content string, senses []Sense) string {
+	type anchor struct {
+		offset int
+		id     string
+	}
+	var anchors []anchor
+	for _, sense := range senses {
+		anchors = append(anchors, anchor{sense.offset, sense.Anchor()})
+		for _, subsection := range sense.Subsections {
+			if subsection.Letter != "" {
+				anchors = append(anchors, anchor{subsection.offset, sense.Anchor() + subsection.Letter})
+			}
+		}
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, a := range anchors {
+		if a.offset < last || !strings.HasPrefix(content[a.offset:], "<p") {
+			continue
+		}
+		tagEnd := strings.IndexByte(content[a.offset:], '>')
+		if tagEnd < 0 || strings.Contains(content[a.offset:a.offset+tagEnd], "id=") {
+			continue
+		}
+		b.WriteString(content[last : a.offset+len("<p")])
+		b.WriteString(` id="` + a.id + `"`)
+		last = a.offset + len("<p")
+	}
+	b.WriteString(content[last:])
+
+	return b.String()
+}
+
+// hasSenseInBlock reports whether the last parsed sense belongs to the given block.
+func hasSenseInBlock(senses []Sense, block int) bool {
+	return len(senses) > 0 && senses[len(senses)-1].Block == block
+}
+
+// parsePartOfSpeech splits a grammatical category paragraph into the category
+// and the specific form it applies to, e.g. "v. aux. [acabar de]".
+func parsePartOfSpeech(text string) (string, string) {
+	partOfSpeech, form, found := strings.Cut(text, "[")
+	if found {
+		form = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(form), "]"))
+	}
+	return strings.TrimSpace(partOfSpeech), form
+}
+
+// parseSenseHeader parses the plain text of a sense header such as
+// "2. [fam.: referit a una dona] embarassada, prenyada, encinta".
+func parseSenseHeader(text string, block int, partOfSpeech, form string) Sense {
+	sense := Sense{
+		Block:        block,
+		PartOfSpeech: partOfSpeech,
+		Form:         form,
+	}
+
+	m := senseNumberPattern.FindStringSubmatch(text)
+	if m != nil {
+		sense.Number = m[1]
+		text = text[len(m[0]):]
+	}
+
+	var glosses []string
+	for strings.HasPrefix(text, "[") {
+		end := strings.IndexByte(text, ']')
+		if end < 0 {
+			break
+		}
+		registers, gloss := parseBracketNote(text[1:end])
+		sense.Registers = append(sense.Registers, registers...)
+		if gloss != "" {
+			glosses = append(glosses, gloss)
+		}
+		text = strings.TrimSpace(text[end+1:])
+	}
+	sense.Gloss = strings.Join(glosses, "; ")
+	sense.Synonyms = splitWordList(text)
+
+	return sense
+}
+
+// parseBracketNote splits the content of a bracketed note into its leading
+// register markers and the remaining explanatory gloss.
+func parseBracketNote(note string) ([]string, string) {
+	var registers []string
+	for {
+		note = strings.TrimLeft(note, " :,;")
+		note = strings.TrimPrefix(note, "i ")
+		marker, length := matchRegisterMarker(note)
+		if marker == "" {
+			break
+		}
+		registers = append(registers, marker)
+		note = note[length:]
+	}
+
+	return registers, strings.TrimSpace(note)
+}
+
+// matchRegisterMarker returns the longest register marker at the start of s,
+// and the length of the matched text. A marker must be followed by the end of
+// the text or a separator. Markers missing the final dot are accepted at the end
+// of the note (e.g. "[pop]").
+func matchRegisterMarker(s string) (string, int) {
+	var match string
+	var length int
+	for _, marker := range registerMarkers {
+		if len(marker) <= len(match) {
+			continue
+		}
+		if s == strings.TrimSuffix(marker, ".") {
+			match, length = marker, len(s)
+			continue
+		}
+		if !strings.HasPrefix(s, marker) {
+			continue
+		}
+		rest := s[len(marker):]
+		if rest == "" || strings.ContainsRune(" :,;", rune(rest[0])) {
+			match, length = marker, len(marker)
+		}
+	}
+	return match, length
+}
+
+// splitWordList splits a comma separated list of words, ignoring commas inside
+// parentheses and brackets, e.g. "caldre (a algú, alguna cosa), tenir necessitat de".
+func splitWordList(text string) []string {
+	var words []string
+	depth, start := 0, 0
+	for i, r := range text {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				words = appendWord(words, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return appendWord(words, text[start:])
+}
+
+// appendWord appends a trimmed word to the list, skipping empty words.
+func appendWord(words []string, word string) []string {
+	word = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(word), "."))
+	if word == "" {
+		return words
+	}
+	return append(words, word)
+}
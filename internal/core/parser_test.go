@@ -0,0 +1,147 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	type block struct{ Tag, Class, ID, Inner string }
+	tests := []struct {
+		html string
+		want []block
+	}{
+		{"", nil},
+		{"text only", nil},
+		{`<p>a</p><hr><p class="x" id="1">b <em>c</em></p>`, []block{
+			{"p", "", "", "a"},
+			{"hr", "", "", ""},
+			{"p", "x", "1", "b <em>c</em>"},
+		}},
+		{`<div class="indent"><p>a</p><p>b</p></div><br/>`, []block{
+			{"div", "indent", "", "<p>a</p><p>b</p>"},
+			{"br", "", "", ""},
+		}},
+		{"<P>a</P>", []block{{"p", "", "", "a"}}},
+		{"</p><p>a</p>", []block{{"p", "", "", "a"}}}, // Stray closing tags are ignored
+		{"<p>a<>b</p>", []block{{"p", "", "", "a<>b"}}},
+		{"<p>a< >b</p>", []block{{"p", "", "", "a< >b"}}},
+		{"<p>a</>b</p>", []block{{"p", "", "", "a</>b"}}},
+		{"<><p>a</p>< >", []block{{"p", "", "", "a"}}},
+		{"<p>a", nil}, // Unclosed
+		{"<p>a<", nil},
+	}
+	for _, tt := range tests {
+		var got []block
+		for _, b := range splitBlocks(tt.html) {
+			got = append(got, block{b.Tag, b.Class, b.ID, b.Inner})
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitBlocks(%q) = %q; want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct{ html, want string }{
+		{"", ""},
+		{"<p> a  <em>b</em>\n c </p>", "a b c"},
+		{"l&#39;aire &amp; el vent", "l'aire & el vent"},
+	}
+	for _, tt := range tests {
+		if got := plainText(tt.html); got != tt.want {
+			t.Errorf("plainText(%q) = %q; want %q", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestParseSenseHeader(t *testing.T) {
+	tests := []struct {
+		text string
+		want Sense
+	}{
+		{"1. vent, ventada, cop de vent", Sense{
+			Number: "1", Synonyms: []string{"vent", "ventada", "cop de vent"},
+		}},
+		{"2. [fam.: referit a una dona] embarassada, prenyada, encinta.", Sense{
+			Number: "2", Registers: []string{"fam."}, Gloss: "referit a una dona",
+			Synonyms: []string{"embarassada", "prenyada", "encinta"},
+		}},
+		{"3. [fig. i pop] [color] blau", Sense{
+			Number: "3", Registers: []string{"fig.", "pop."}, Gloss: "color", Synonyms: []string{"blau"},
+		}},
+		{"4. [cat. val.] caldre (a algú, alguna cosa), tenir necessitat de", Sense{
+			Number: "4", Registers: []string{"cat. val."},
+			Synonyms: []string{"caldre (a algú, alguna cosa)", "tenir necessitat de"},
+		}},
+		{"5. [popular] mot", Sense{Number: "5", Gloss: "popular", Synonyms: []string{"mot"}}},
+		{"6.", Sense{Number: "6"}},
+	}
+	for _, tt := range tests {
+		if got := parseSenseHeader(tt.text, 0, "", ""); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSenseHeader(%q) = %+v; want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParsePartOfSpeech(t *testing.T) {
+	tests := []struct{ text, partOfSpeech, form string }{
+		{"m.", "m.", ""},
+		{"v. aux. [acabar de]", "v. aux.", "acabar de"},
+		{"v. tr. [acostar-se a ]", "v. tr.", "acostar-se a"},
+	}
+	for _, tt := range tests {
+		partOfSpeech, form := parsePartOfSpeech(tt.text)
+		if partOfSpeech != tt.partOfSpeech || form != tt.form {
+			t.Errorf("parsePartOfSpeech(%q) = %q, %q; want %q, %q", tt.text, partOfSpeech, form, tt.partOfSpeech, tt.form)
+		}
+	}
+}
+
+func TestParseEntryContent(t *testing.T) {
+	content := `<p>Vegeu també vent.</p>` +
+		`<p>m.</p>` +
+		`<p><strong>1.</strong> vent, ventada</p>` +
+		`<div><p><em>a)</em> <span class="smallcaps">Explicacions d'ús</span></p><p>Primer paràgraf.</p></div>` +
+		`<div><p>d) <span class="smallcaps">Modismes i fraseologia</span></p><p>viure de l'aire</p></div>` +
+		`<p><strong>2.</strong> [fig.] aparença</p>` +
+		`<p>Paràgraf sense subsecció.</p>` +
+		`<hr>` +
+		`<p>v. tr. [airejar]</p>` +
+		`<p><strong>1.</strong> ventilar</p>` +
+		`<p>a< >b<>c</p>`
+
+	senses, notes := parseEntryContent(content)
+	if want := []string{"Vegeu també vent."}; !reflect.DeepEqual(notes, want) {
+		t.Errorf("notes = %q; want %q", notes, want)
+	}
+
+	type sense struct {
+		Anchor, PartOfSpeech, Form string
+		Synonyms                   []string
+		Subsections                []Subsection
+	}
+	want := []sense{
+		{"1", "m.", "", []string{"vent", "ventada"}, []Subsection{
+			{Letter: "a", Title: "Explicacions d'ús", Paragraphs: []string{"Primer paràgraf."}},
+			{Letter: "d", Title: "Modismes i fraseologia", Paragraphs: []string{"viure de l'aire"}},
+		}},
+		{"2", "m.", "", []string{"aparença"}, []Subsection{
+			{Paragraphs: []string{"Paràgraf sense subsecció."}},
+		}},
+		{"2-1", "v. tr.", "airejar", []string{"ventilar"}, []Subsection{
+			{Paragraphs: []string{"a< >b<>c"}},
+		}},
+	}
+	var got []sense
+	for _, s := range senses {
+		var subsections []Subsection
+		for _, sub := range s.Subsections {
+			subsections = append(subsections, Subsection{Letter: sub.Letter, Title: sub.Title, Paragraphs: sub.Paragraphs})
+		}
+		got = append(got, sense{s.Anchor(), s.PartOfSpeech, s.Form, s.Synonyms, subsections})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEntryContent() senses = %+v; want %+v", got, want)
+	}
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Lexical relation kinds.
+const (
+	RelationAntonym      = "antonym"
+	RelationRelated      = "related"
+	RelationDerivative   = "derivative"
+	RelationDiminutive   = "diminutive"
+	RelationAugmentative = "augmentative"
+	RelationPejorative   = "pejorative"
+)
+
+// RelationKinds lists the lexical relation kinds in the order they are shown in entry pages.
+var RelationKinds = []string{
+	RelationAntonym,
+	RelationRelated,
+	RelationDerivative,
+	RelationDiminutive,
+	RelationAugmentative,
+	RelationPejorative,
+}
+
+// relationLabels maps each relation kind to its label in entry pages.
+var relationLabels = map[string]string{
+	RelationAntonym:      "Antònims",
+	RelationRelated:      "Mots relacionats",
+	RelationDerivative:   "Derivats",
+	RelationDiminutive:   "Diminutius",
+	RelationAugmentative: "Augmentatius",
+	RelationPejorative:   "Pejoratius",
+}
+
+// relationMarkers maps the markers written at the start of a relation paragraph
+// to their relation kind.
+var relationMarkers = map[string]string{
+	"Ant":      RelationAntonym,
+	"Rel":      RelationRelated,
+	"Der":      RelationDerivative,
+	"Derivat":  RelationDerivative,
+	"Derivats": RelationDerivative,
+	"Dim":      RelationDiminutive,
+	"Augm":     RelationAugmentative,
+	"Pej":      RelationPejorative,
+}
+
+// relationPattern matches the start of a relation paragraph, such as "Ant.:",
+// "Rel.:", "Derivats:" or "Derivat i Mot Relacionat:", in plain text.
+var relationPattern = regexp.MustCompile(
+	`^(Ant|Rel|Der|Derivats?|Dim|Augm|Pej)\b\.?(?:\s*(?:i|-)\s*Mot Relacionat)?\s*\.?\s*:\s*`)
+
+// Relation represents a typed lexical relation between a sense of an entry and
+// a list of words, as written in the "Altres recursos lexicals" subsection,
+// e.g. "Ant.: tardor, primavera d'hivern".
+type Relation struct {
+	// Kind is the relation kind (RelationAntonym, RelationRelated, ...).
+	Kind string
+
+	// Slug is the slug of the entry the relation belongs to.
+	Slug string
+
+	// PartOfSpeech and Sense identify the sense the relation belongs to.
+	// They are empty if the relation is written outside any sense.
+	PartOfSpeech string
+	Sense        string
+
+	// Anchor is the id of the sense in the entry page, if any.
+	Anchor string
+
+	// senseIndex is the index of the sense in the senses of the entry, or -1 if the
+	// relation is written outside any sense. Sense numbers may be repeated in the data.
+	senseIndex int
+
+	// Targets are the related words, in content order.
+	Targets []RelationTarget
+}
+
+// RelationTarget represents a word of a lexical relation.
+type RelationTarget struct {
+	// Word is the related word, without notes (e.g. "inintel·ligible").
+	Word string
+
+	// Note is the register marker or explanation written along the word,
+	// e.g. "cult." in "inintel·ligible (cult.)".
+	Note string
+
+	// Group is the index of the group of words the target belongs to. Groups are
+	// separated by "//" in the content, e.g. "deslligar // deslligar-se, desfer-se".
+	Group int
+
+	// Slug is the slug of the entry for the word, if one exists.
+	Slug string
+}
+
+// parseRelations extracts the lexical relations of an entry from its senses and notes.
+// It must be called after entryIndexBySlug is built, to resolve the related words.
+func parseRelations(entry Entry, entrySlugsByWord map[string]string) []Relation {
+	var relations []Relation
+	for _, p := range entry.Notes {
+		if relation, ok := parseRelation(p, entrySlugsByWord); ok {
+			relation.Slug = entry.Slug
+			relation.senseIndex = -1
+			relations = append(relations, relation)
+		}
+	}
+	for i, sense := range entry.Senses {
+		for _, subsection := range sense.Subsections {
+			for _, p := range subsection.Paragraphs {
+				relation, ok := parseRelation(p, entrySlugsByWord)
+				if !ok {
+					continue
+				}
+				relation.Slug = entry.Slug
+				relation.PartOfSpeech = sense.PartOfSpeech
+				relation.Sense = sense.Number
+				relation.Anchor = sense.Anchor()
+				relation.senseIndex = i
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations
+}
+
+// parseRelation parses a relation paragraph such as
+// `<span class="smallcaps"><strong>Ant</strong>.</span>: tardor, primavera d'hivern`.
+// It reports false if the paragraph is not a relation.
+func parseRelation(p string, entrySlugsByWord map[string]string) (Relation, bool) {
+	text := plainText(p)
+	m := relationPattern.FindStringSubmatch(text)
+	if m == nil {
+		return Relation{}, false
+	}
+	relation := Relation{Kind: relationMarkers[m[1]]}
+
+	// Words linked in the paragraph are resolved from their links.
+	linkedSlugs := make(map[string]string)
+	for _, link := range linkPattern.FindAllStringSubmatch(p, -1) {
+		if slug, ok := resolveEntrySlug(strings.TrimPrefix(link[1], "/lema/")); ok {
+			linkedSlugs[plainText(link[2])] = slug
+		}
+	}
+
+	for group, words := range strings.Split(text[len(m[0]):], "//") {
+		for _, item := range splitWordList(words) {
+			// Alternatives are written as "alt / gros".
+			for _, alternative := range strings.Split(item, " / ") {
+				target := parseRelationTarget(alternative)
+				if target.Word == "" {
+					continue
+				}
+				target.Group = group
+				if slug, ok := linkedSlugs[target.Word]; ok {
+					target.Slug = slug
+				} else {
+					target.Slug = entrySlugsByWord[target.Word]
+				}
+				relation.Targets = append(relation.Targets, target)
+			}
+		}
+	}
+
+	return relation, len(relation.Targets) > 0
+}
+
+// parseRelationTarget splits a word of a relation list from the bracketed and
+// parenthesized notes written before or after it, e.g. "[fam.] postureta" or
+// "desfer-se (de)". Parenthesized prepositions are kept as part of the word.
+func parseRelationTarget(item string) RelationTarget {
+	var target RelationTarget
+	var notes []string
+
+	item = strings.TrimSpace(item)
+	for strings.HasPrefix(item, "[") {
+		end := strings.IndexByte(item, ']')
+		if end < 0 {
+			break
+		}
+		notes = append(notes, strings.TrimSpace(item[1:end]))
+		item = strings.TrimSpace(item[end+1:])
+	}
+	var trailing []string
+	for strings.HasSuffix(item, ")") || strings.HasSuffix(item, "]") {
+		open := strings.LastIndexAny(item, "([")
+		if open <= 0 {
+			break
+		}
+		note := strings.TrimSpace(item[open+1 : len(item)-1])
+		if item[open] == '(' && !strings.ContainsAny(note, ". ") {
+			// A governed preposition, e.g. "desfer-se (de)", is part of the word.
+			break
+		}
+		trailing = append([]string{note}, trailing...)
+		item = strings.TrimSpace(item[:open])
+	}
+	notes = append(notes, trailing...)
+
+	target.Word = strings.TrimSpace(item)
+	target.Note = strings.Join(notes, "; ")
+	return target
+}
+
+// buildEntrySlugsByWord maps the words of entry titles to their entry slugs, so that
+// related words can be resolved. Titles with variants such as "trist | trista" or
+// "servei (o servici)" are also indexed by their first form.
+func buildEntrySlugsByWord() map[string]string {
+	slugs := make(map[string]string, len(AllEntries))
+	add := func(word, slug string) {
+		word = strings.TrimSpace(word)
+		if _, ok := slugs[word]; !ok && word != "" {
+			slugs[word] = slug
+		}
+	}
+	for _, entry := range AllEntries {
+		title := plainText(entry.DisplayTitle)
+		add(title, entry.Slug)
+		form, _, _ := strings.Cut(title, "|")
+		if i := strings.IndexAny(form, "(["); i > 0 {
+			form = form[:i]
+		}
+		add(form, entry.Slug)
+	}
+	return slugs
+}
+
+// GetRelations returns the lexical relations of the given kind in all entries,
+// in dictionary order. An empty kind returns the relations of every kind.
+func GetRelations(kind string) []Relation {
+	var relations []Relation
+	for _, entry := range AllEntries {
+		for _, relation := range entry.Relations {
+			if kind == "" || relation.Kind == kind {
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations
+}
+
+// GetEntryRelations returns the lexical relations of the entry with the given slug.
+func GetEntryRelations(slug string) []Relation {
+	i, ok := entryIndexBySlug[slug]
+	if !ok {
+		return nil
+	}
+	return AllEntries[i].Relations
+}
+
+// groupRelations groups relations by kind, in the order of RelationKinds.
+func groupRelations(relations []Relation) []RelationGroup {
+	var groups []RelationGroup
+	for _, kind := range RelationKinds {
+		group := RelationGroup{Kind: kind, Label: relationLabels[kind]}
+		for _, relation := range relations {
+			if relation.Kind == kind {
+				group.Relations = append(group.Relations, relation)
+			}
+		}
+		if len(group.Relations) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
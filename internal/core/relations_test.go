@@ -0,0 +1,71 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRelationTarget(t *testing.T) {
+	tests := []struct {
+		item string
+		want RelationTarget
+	}{
+		{"tardor", RelationTarget{Word: "tardor"}},
+		{" inintel·ligible (cult.) ", RelationTarget{Word: "inintel·ligible", Note: "cult."}},
+		{"[fam.] postureta", RelationTarget{Word: "postureta", Note: "fam."}},
+		{"desfer-se (de)", RelationTarget{Word: "desfer-se (de)"}},
+		{"[fam.] mot (poc us.) [cat. val.]", RelationTarget{Word: "mot", Note: "fam.; poc us.; cat. val."}},
+		{"(cult.)", RelationTarget{Word: "(cult.)"}},
+		{"", RelationTarget{}},
+	}
+	for _, tt := range tests {
+		if got := parseRelationTarget(tt.item); got != tt.want {
+			t.Errorf("parseRelationTarget(%q) = %+v; want %+v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestParseRelation(t *testing.T) {
+	withEntries(t, "deslligar")
+	slugsByWord := map[string]string{"tardor": "tardor", "primavera": "primavera"}
+	tests := []struct {
+		p    string
+		want Relation
+		ok   bool
+	}{
+		{
+			`<span class="smallcaps"><strong>Ant</strong>.</span>: tardor, primavera d'hivern`,
+			Relation{Kind: RelationAntonym, Targets: []RelationTarget{
+				{Word: "tardor", Slug: "tardor"},
+				{Word: "primavera d'hivern"},
+			}},
+			true,
+		},
+		{
+			`Derivat i Mot Relacionat: <a href="/lema/deslligar">deslligar</a> // deslligar-se, alt / gros`,
+			Relation{Kind: RelationDerivative, Targets: []RelationTarget{
+				{Word: "deslligar", Slug: "deslligar"},
+				{Word: "deslligar-se", Group: 1},
+				{Word: "alt", Group: 1},
+				{Word: "gros", Group: 1},
+			}},
+			true,
+		},
+		{
+			`Rel.: [fam.] primavera (cult.)`,
+			Relation{Kind: RelationRelated, Targets: []RelationTarget{
+				{Word: "primavera", Note: "fam.; cult.", Slug: "primavera"},
+			}},
+			true,
+		},
+		{`Dim.: `, Relation{}, false},
+		{`Antigament: tardor`, Relation{}, false},
+		{`El mot tardor`, Relation{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRelation(tt.p, slugsByWord)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseRelation(%q) = %+v, %v; want %+v, %v", tt.p, got, ok, tt.want, tt.ok)
+		}
+	}
+}
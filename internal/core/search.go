@@ -0,0 +1,91 @@
+package core
+
+import (
+	"html/template"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Title match kinds, from the most to the least relevant.
+const (
+	MatchExact     = "exact"
+	MatchPrefix    = "prefix"
+	MatchSubstring = "substring"
+	MatchFuzzy     = "fuzzy" // Suggestion for a misspelled query, see SuggestEntries
+)
+
+// TitleMatch represents an entry whose title matches a search query.
+type TitleMatch struct {
+	Slug         string        `json:"slug"`
+	DisplayTitle template.HTML `json:"title"`
+	URL          string        `json:"url"`
+	Match        string        `json:"match"` // MatchExact, MatchPrefix, MatchSubstring or MatchFuzzy
+}
+
+// SearchTitles returns the entries whose normalized title matches the query,
+// ignoring case and accents. Exact matches come first, then titles starting with
+// the query, then titles containing it, each group in dictionary order.
+// A title matches exactly if any of its forms is the query, e.g. "adulta" matches
+// "adult | adulta" and "adonar-se" matches "adonar-se (de)".
+func SearchTitles(query string) []TitleMatch {
+	query = normalizeTitle(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	var exact, prefix, substring []TitleMatch
+	for _, entry := range AllEntries {
+		match := TitleMatch{
+			Slug:         entry.Slug,
+			DisplayTitle: template.HTML(entry.DisplayTitle),
+			URL:          EntryPath(entry.Slug),
+		}
+		forms := titleForms(entry.NormalizedTitle)
+		switch {
+		case slices.Contains(forms, query):
+			match.Match = MatchExact
+			exact = append(exact, match)
+		case slices.ContainsFunc(forms, func(form string) bool { return strings.HasPrefix(form, query) }):
+			match.Match = MatchPrefix
+			prefix = append(prefix, match)
+		case strings.Contains(entry.NormalizedTitle, query):
+			match.Match = MatchSubstring
+			substring = append(substring, match)
+		}
+	}
+
+	return append(append(exact, prefix...), substring...)
+}
+
+// ExactTitleMatches returns the entries with a title form equal to the query, to
+// link them from the full-text search results.
+func ExactTitleMatches(query string) []TitleMatch {
+	var exact []TitleMatch
+	for _, match := range SearchTitles(query) {
+		if match.Match == MatchExact {
+			exact = append(exact, match)
+		}
+	}
+	return exact
+}
+
+// titleForms returns the forms of a normalized title: the whole title, each of its
+// variants separated by "|", and each variant without its trailing notes, e.g.
+// "adonar-se (de)" gives "adonar-se (de)" and "adonar-se".
+func titleForms(title string) []string {
+	forms := []string{title}
+	for _, form := range strings.Split(title, "|") {
+		form = strings.TrimSpace(form)
+		forms = append(forms, form)
+		if i := strings.IndexAny(form, "(["); i > 0 {
+			forms = append(forms, strings.TrimSpace(form[:i]))
+		}
+	}
+	return forms
+}
+
+// EntryPath returns the URL path of the entry page with the given slug.
+func EntryPath(slug string) string {
+	return "/lema/" + url.PathEscape(slug)
+}
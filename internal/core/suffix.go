@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// Limits of the suffix patterns, which are user input.
+const (
+	MaxSuffixPatternLength = 50 // Characters, after normalizing
+	MaxSuffixWildcards     = 10
+)
+
+// Kinds of the words of the suffix index.
+const (
+	WordEntry   = "entry"   // Title of an entry
+	WordSynonym = "synonym" // Word listed as a synonym or related word (see GetSynonymSources)
+)
+
+// WordMatch represents a word ending with a suffix pattern.
+type WordMatch struct {
+	Word string `json:"word"` // As written in the dictionary
+	Kind string `json:"kind"` // WordEntry or WordSynonym
+	URL  string `json:"url"`  // Entry page, or synonym page for synonyms
+}
+
+// suffixIndexItem is a word of the suffix index.
+type suffixIndexItem struct {
+	reversed []rune // Normalized word, reversed
+	rank     int    // Position of the word in Catalan collation order
+	match    WordMatch
+}
+
+// buildSuffixIndex builds the index of the entry titles and the words listed as
+// synonyms or related words, sorted by their reversed normalized form, so that the
+// words ending with a suffix are contiguous. Each variant of a title (e.g. "bo" and
+// "bona" in "bo | bona") is a word of its own; synonyms that are also titles are
+// only indexed as titles. It must be called after the synonym sources are built.
+func buildSuffixIndex() []suffixIndexItem {
+	var index []suffixIndexItem
+	seen := make(map[string]bool)
+	add := func(word, kind, url string) {
+		normalized := normalizeTitle(word)
+		if normalized == "" || seen[normalized] {
+			return
+		}
+		seen[normalized] = true
+		reversed := []rune(normalized)
+		slices.Reverse(reversed)
+		index = append(index, suffixIndexItem{reversed: reversed, match: WordMatch{word, kind, url}})
+	}
+
+	for _, entry := range AllEntries {
+		for _, form := range strings.Split(plainText(entry.DisplayTitle), "|") {
+			if i := strings.IndexAny(form, "(["); i > 0 {
+				form = form[:i]
+			}
+			add(strings.TrimSpace(form), WordEntry, EntryPath(entry.Slug))
+		}
+	}
+	for _, word := range GetSynonymWords() {
+		add(word, WordSynonym, "/sinonim/"+url.PathEscape(strings.ReplaceAll(word, " ", "_")))
+	}
+
+	// Each word gets its rank in Catalan collation order, so that the matches can be
+	// sorted by rank: comparing the words is slow for the thousands of matches of
+	// patterns such as "*".
+	slices.SortFunc(index, func(a, b suffixIndexItem) int {
+		return catalan.Compare(a.match.Word, b.match.Word)
+	})
+	for i := range index {
+		index[i].rank = i
+	}
+	slices.SortFunc(index, func(a, b suffixIndexItem) int {
+		return slices.Compare(a.reversed, b.reversed)
+	})
+	return index
+}
+
+// SearchSuffix returns the entry titles and the synonyms ending with the given
+// pattern, ignoring case and accents, in Catalan collation order. In the pattern,
+// "?" matches any single character and "*" matches any sequence of characters,
+// e.g. "a?re" matches the words ending in "aire" and in "arre". It returns an error
+// if the pattern is longer than MaxSuffixPatternLength characters or has more than
+// MaxSuffixWildcards wildcards.
+func SearchSuffix(pattern string) ([]WordMatch, error) {
+	pattern = normalizeTitle(strings.TrimSpace(pattern))
+	for strings.Contains(pattern, "**") {
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+	}
+	if pattern == "" {
+		return nil, nil
+	}
+	if n := len([]rune(pattern)); n > MaxSuffixPatternLength {
+		return nil, fmt.Errorf("pattern longer than %d characters", MaxSuffixPatternLength)
+	}
+	if n := strings.Count(pattern, "?") + strings.Count(pattern, "*"); n > MaxSuffixWildcards {
+		return nil, fmt.Errorf("pattern with more than %d wildcards", MaxSuffixWildcards)
+	}
+	reversed := []rune(pattern)
+	slices.Reverse(reversed)
+
+	// Words ending with the literal suffix after the last wildcard are contiguous in
+	// the index; the rest of the pattern is matched against each of them.
+	literal := reversed
+	if i := slices.IndexFunc(reversed, isWildcard); i >= 0 {
+		literal = reversed[:i]
+	}
+	start := sort.Search(len(suffixIndex), func(i int) bool {
+		return slices.Compare(suffixIndex[i].reversed, literal) >= 0
+	})
+
+	var items []suffixIndexItem
+	for _, item := range suffixIndex[start:] {
+		if !hasRunePrefix(item.reversed, literal) {
+			break
+		}
+		if matchWildcards(reversed, item.reversed) {
+			items = append(items, item)
+		}
+	}
+
+	slices.SortFunc(items, func(a, b suffixIndexItem) int {
+		return a.rank - b.rank
+	})
+	var matches []WordMatch
+	for _, item := range items {
+		matches = append(matches, item.match)
+	}
+	return matches, nil
+}
+
+// GetSuffixWords returns the words of the suffix index in Catalan collation order,
+// for the suffix search of the static site.
+func GetSuffixWords() []WordMatch {
+	words := make([]WordMatch, len(suffixIndex))
+	for _, item := range suffixIndex {
+		words[item.rank] = item.match
+	}
+	return words
+}
+
+func isWildcard(r rune) bool {
+	return r == '?' || r == '*'
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	return len(s) >= len(prefix) && slices.Equal(s[:len(prefix)], prefix)
+}
+
+// matchWildcards reports whether a reversed pattern matches the start of a reversed
+// word, i.e. whether the pattern matches the end of the word. On a mismatch, it
+// backtracks to the last "*" only, which is enough for glob patterns and keeps the
+// matching linear in the length of the word for each "*".
+func matchWildcards(pattern, word []rune) bool {
+	p, w := 0, 0
+	star, mark := -1, 0 // Position of the last "*" in the pattern, and of the word when reached
+	for p < len(pattern) {
+		switch {
+		case pattern[p] == '*':
+			star, mark = p, w
+			p++
+		case w < len(word) && (pattern[p] == '?' || pattern[p] == word[w]):
+			p++
+			w++
+		case star >= 0 && mark < len(word):
+			mark++
+			p, w = star+1, mark
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchWildcards(t *testing.T) {
+	tests := []struct {
+		pattern, word string
+		want          bool
+	}{
+		{"aire", "aire", true},
+		{"aire", "caire", true},
+		{"aire", "aira", false},
+		{"aire", "ire", false},
+		{"a?re", "aire", true},
+		{"a?re", "carre", true},
+		{"a?re", "are", false},
+		{"a*re", "are", true},
+		{"a*re", "alegre", true},
+		{"a*re", "alegra", false},
+		{"*re", "re", true},
+		{"?", "", false},
+		{"?", "a", true},
+		{"*", "", true},
+		{"", "mot", true},
+		{"b*a*a", "banana", true},
+		{"b*a*a", "bebé", false},
+		{"*?a", "a", false},
+		{"*?a", "ba", true},
+	}
+	for _, tt := range tests {
+		// Patterns and words are matched reversed, from their end.
+		pattern, word := []rune(tt.pattern), []rune(tt.word)
+		slices.Reverse(pattern)
+		slices.Reverse(word)
+		if got := matchWildcards(pattern, word); got != tt.want {
+			t.Errorf("matchWildcards(%q, %q) = %v; want %v", tt.pattern, tt.word, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkMatchWildcards matches the worst case of the limits of the patterns, which
+// backtracks at each "*", against a long word.
+func BenchmarkMatchWildcards(b *testing.B) {
+	pattern := []rune(strings.Repeat("*?", MaxSuffixWildcards/2) + "q")
+	word := []rune(strings.Repeat("a", 10000))
+	for b.Loop() {
+		matchWildcards(pattern, word)
+	}
+}
+
+func TestSearchSuffixLimits(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{"", true},
+		{strings.Repeat("a", MaxSuffixPatternLength), true},
+		{strings.Repeat("a", MaxSuffixPatternLength+1), false},
+		{strings.Repeat("?", MaxSuffixWildcards), true},
+		{strings.Repeat("?", MaxSuffixWildcards+1), false},
+		{"q*?*?*?*?*?*?*?*?*?*?*", false},
+		{"q" + strings.Repeat("*", 100), true}, // Consecutive "*" count as one
+	}
+	for _, tt := range tests {
+		_, err := SearchSuffix(tt.pattern)
+		if (err == nil) != tt.valid {
+			t.Errorf("SearchSuffix(%q) error = %v; want valid %v", tt.pattern, err, tt.valid)
+		}
+	}
+}
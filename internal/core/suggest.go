@@ -0,0 +1,130 @@
+package core
+
+import (
+	"cmp"
+	"html/template"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is the maximum number of entries suggested for a misspelled title.
+const maxSuggestions = 5
+
+// spellingReplacer folds the spellings commonly confused when typing a Catalan
+// word, so that they do not count as errors: "l·l" typed as "ll", "ny" typed as
+// "n" or as the Spanish "ñ", "ç" typed as "c", and "v" typed as "b".
+// It is applied to normalized text, which has no accents.
+var spellingReplacer = strings.NewReplacer(
+	"l·l", "ll",
+	"ñ", "n",
+	"ny", "n",
+	"ç", "c",
+	"v", "b",
+)
+
+// SuggestEntries returns the entries whose titles are closest to a misspelled
+// query by edit distance, for "did you mean" suggestions. Accents, case, and the
+// spellings folded by spellingReplacer are ignored. Titles are compared by each
+// of their forms (see titleForms), and only titles close enough to the query,
+// relative to its length, are suggested.
+func SuggestEntries(query string) []TitleMatch {
+	query = normalizeTitle(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	folded := spellingReplacer.Replace(query)
+	length := utf8.RuneCountInString(folded)
+	maxDistance := max(1, length/3)
+
+	// The distance is at least the difference in length, so queries much longer than
+	// any title, e.g. long URLs of missing entries, are not compared at all.
+	if length > longestTitleLength()+maxDistance {
+		return nil
+	}
+
+	type candidate struct {
+		entry    int
+		distance int // Distance between the folded forms
+		exact    int // Distance between the normalized forms, to break ties
+	}
+	var candidates []candidate
+	for i, entry := range AllEntries {
+		best := candidate{distance: maxDistance + 1}
+		for _, form := range titleForms(entry.NormalizedTitle) {
+			foldedForm := spellingReplacer.Replace(form)
+			if abs(utf8.RuneCountInString(foldedForm)-length) > maxDistance {
+				continue
+			}
+			c := candidate{i, editDistance(folded, foldedForm), editDistance(query, form)}
+			if c.distance < best.distance || (c.distance == best.distance && c.exact < best.exact) {
+				best = c
+			}
+		}
+		if best.distance <= maxDistance {
+			candidates = append(candidates, best)
+		}
+	}
+
+	// Entries are in dictionary order, so a stable sort keeps it for ties.
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.exact, b.exact))
+	})
+
+	var suggestions []TitleMatch
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		entry := AllEntries[c.entry]
+		suggestions = append(suggestions, TitleMatch{
+			Slug:         entry.Slug,
+			DisplayTitle: template.HTML(entry.DisplayTitle),
+			URL:          EntryPath(entry.Slug),
+			Match:        MatchFuzzy,
+		})
+	}
+	return suggestions
+}
+
+// longestTitleLength returns the length in runes of the longest normalized title,
+// which is not shorter than any of its forms, folded or not.
+func longestTitleLength() int {
+	longest := 0
+	for _, entry := range AllEntries {
+		longest = max(longest, utf8.RuneCountInString(entry.NormalizedTitle))
+	}
+	return longest
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// editDistance returns the edit distance between two strings, in runes: the number
+// of insertions, deletions, substitutions and transpositions of adjacent runes needed
+// to turn one into the other (optimal string alignment distance).
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	rows := [3][]int{make([]int, len(t)+1), make([]int, len(t)+1), make([]int, len(t)+1)}
+	for j := range rows[1] {
+		rows[1][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		prev2, prev, curr := rows[0], rows[1], rows[2]
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		rows = [3][]int{prev, curr, prev2}
+	}
+	return rows[1][len(t)]
+}
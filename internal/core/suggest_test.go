@@ -0,0 +1,68 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"aire", "aire", 0},
+		{"aire", "", 4},
+		{"aire", "aires", 1},
+		{"aire", "air", 1},
+		{"aire", "eire", 1},
+		{"aire", "arie", 1}, // Transposition
+		{"cafè", "cafe", 1}, // Runes, not bytes
+		{"gat", "cotxe", 4},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// withEntries replaces the entries for the duration of a test.
+func withEntries(t *testing.T, titles ...string) {
+	t.Helper()
+	savedEntries, savedIndex := AllEntries, entryIndexBySlug
+	t.Cleanup(func() { AllEntries, entryIndexBySlug = savedEntries, savedIndex })
+	AllEntries, entryIndexBySlug = nil, make(map[string]int)
+	for i, title := range titles {
+		slug := strings.ReplaceAll(title, " ", "_")
+		AllEntries = append(AllEntries, Entry{Slug: slug, DisplayTitle: title, NormalizedTitle: normalizeTitle(slug)})
+		entryIndexBySlug[slug] = i
+	}
+}
+
+func TestSuggestEntries(t *testing.T) {
+	withEntries(t, "aire", "cavall", "trist | trista", "paella", "col·lecció", "anyell")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"aira", []string{"aire"}},
+		{"cabal", []string{"cavall"}},           // "v" typed as "b", and "ll" as "l"
+		{"tristes", []string{"trist_|_trista"}}, // Compared by form
+		{"coleccio", []string{"col·lecció"}},
+		{"añell", []string{"anyell"}},
+		{"xyz", nil},
+		{strings.Repeat("a", 50000), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, match := range SuggestEntries(tt.query) {
+			got = append(got, match.Slug)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SuggestEntries(%.20q) = %v; want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core/catalan"
+)
+
+// SynonymKind is the kind of the words listed in sense headers, which are synonyms
+// of the entry in that sense. Words listed in "Altres recursos lexicals" keep their
+// relation kind (RelationAntonym, RelationRelated, ...).
+const SynonymKind = "synonym"
+
+// synonymKinds lists the kinds of word references in the order they are shown in
+// synonym pages, with their labels.
+var synonymKinds = []struct {
+	Kind  string
+	Label string
+}{
+	{SynonymKind, "Sinònim de"},
+	{RelationRelated, "Mot relacionat amb"},
+	{RelationDerivative, "Derivat de"},
+	{RelationDiminutive, "Diminutiu de"},
+	{RelationAugmentative, "Augmentatiu de"},
+	{RelationPejorative, "Pejoratiu de"},
+	{RelationAntonym, "Antònim de"},
+}
+
+// SynonymSource represents a sense of an entry that lists a word, as a synonym in
+// its header or as a related word in its "Altres recursos lexicals" subsection.
+type SynonymSource struct {
+	// Word is the word as written in the entry, without notes.
+	Word string `json:"word"`
+
+	// Note is the register marker or explanation written along the word, if any.
+	Note string `json:"note,omitempty"`
+
+	// Kind is SynonymKind or the relation kind (RelationRelated, RelationAntonym, ...).
+	Kind string `json:"kind"`
+
+	// Slug is the slug of the entry listing the word.
+	Slug string `json:"slug"`
+
+	// DisplayTitle is the display title of the entry listing the word.
+	DisplayTitle template.HTML `json:"title"`
+
+	// PartOfSpeech and Sense identify the sense listing the word. They are empty
+	// for related words written outside any sense.
+	PartOfSpeech string `json:"part_of_speech,omitempty"`
+	Sense        string `json:"sense,omitempty"`
+
+	// URL is the path of the entry page, including the anchor of the sense if any.
+	URL string `json:"url"`
+}
+
+// SynonymGroup groups the sources of a word of the same kind, in synonym pages.
+type SynonymGroup struct {
+	Kind    string
+	Label   string
+	Sources []SynonymSource
+}
+
+// buildSynonymSources builds the reverse index of the words listed as synonyms in
+// sense headers and as related words in entries, mapping each normalized word to
+// the senses listing it, in dictionary order. It must be called after the
+// relations of all entries are parsed.
+func buildSynonymSources() map[string][]SynonymSource {
+	sources := make(map[string][]SynonymSource)
+	add := func(entry Entry, kind, partOfSpeech, sense, anchor string, target RelationTarget) {
+		if target.Word == "" {
+			return
+		}
+		source := SynonymSource{
+			Word:         target.Word,
+			Note:         target.Note,
+			Kind:         kind,
+			Slug:         entry.Slug,
+			DisplayTitle: template.HTML(entry.DisplayTitle),
+			PartOfSpeech: partOfSpeech,
+			Sense:        sense,
+			URL:          EntryPath(entry.Slug),
+		}
+		if anchor != "" {
+			source.URL += "#" + anchor
+		}
+		key := normalizeTitle(target.Word)
+		sources[key] = append(sources[key], source)
+	}
+
+	for _, entry := range AllEntries {
+		for _, sense := range entry.Senses {
+			for _, word := range sense.Synonyms {
+				target := parseRelationTarget(strings.TrimRight(word, "!?"))
+				add(entry, SynonymKind, sense.PartOfSpeech, sense.Number, sense.Anchor(), target)
+			}
+		}
+		for _, relation := range entry.Relations {
+			for _, target := range relation.Targets {
+				add(entry, relation.Kind, relation.PartOfSpeech, relation.Sense, relation.Anchor, target)
+			}
+		}
+	}
+
+	return sources
+}
+
+// GetSynonymSources returns the senses listing the given word as a synonym or as a
+// related word, ignoring case, accents and underscores instead of spaces.
+func GetSynonymSources(word string) []SynonymSource {
+	return synonymSourcesByWord[normalizeTitle(strings.TrimSpace(word))]
+}
+
+// GetSynonymWords returns the words listed as synonyms or related words, as written
+// in the first entry listing them, in Catalan collation order.
+func GetSynonymWords() []string {
+	words := make([]string, 0, len(synonymSourcesByWord))
+	for _, sources := range synonymSourcesByWord {
+		words = append(words, sources[0].Word)
+	}
+	slices.SortFunc(words, catalan.Compare)
+	return words
+}
+
+// GetSynonymIndexShards returns the reverse index of synonyms and related words
+// grouped by shard (see TextIndexShard), for the static search. Words are
+// normalized as in GetSynonymSources.
+func GetSynonymIndexShards() map[string]map[string][]SynonymSource {
+	shards := make(map[string]map[string][]SynonymSource)
+	for word, sources := range synonymSourcesByWord {
+		shard := TextIndexShard(word)
+		if shards[shard] == nil {
+			shards[shard] = make(map[string][]SynonymSource)
+		}
+		shards[shard][word] = sources
+	}
+	return shards
+}
+
+// groupSynonymSources groups the sources of a word by kind, in the order of synonymKinds.
+func groupSynonymSources(sources []SynonymSource) []SynonymGroup {
+	var groups []SynonymGroup
+	for _, kind := range synonymKinds {
+		group := SynonymGroup{Kind: kind.Kind, Label: kind.Label}
+		for _, source := range sources {
+			if source.Kind == kind.Kind {
+				group.Sources = append(group.Sources, source)
+			}
+		}
+		if len(group.Sources) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
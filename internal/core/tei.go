@@ -0,0 +1,388 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// teiNamespace is the namespace of TEI documents.
+const teiNamespace = "http://www.tei-c.org/ns/1.0"
+
+// teiPartsOfSpeech maps the grammatical categories of the senses to Universal
+// Dependencies tags, the normalized values of TEI Lex-0. Locutions and categories
+// that are not listed keep only the written form.
+var teiPartsOfSpeech = map[string]string{
+	"adj":    "ADJ",
+	"adv":    "ADV",
+	"conj":   "CCONJ",
+	"f":      "NOUN",
+	"interj": "INTJ",
+	"m":      "NOUN",
+	"prep":   "ADP",
+	"pron":   "PRON",
+	"v":      "VERB",
+}
+
+// teiUsageTypes maps the register markers (see registerMarkers) to TEI Lex-0 usage
+// types. Regional markers ("cat. val.", ...) are geographic, and the rest are hints.
+var teiUsageTypes = map[string]string{
+	"ant.":     "dating",
+	"arc.":     "dating",
+	"obs.":     "dating",
+	"poc us.":  "frequency",
+	"abs.":     "meaningType",
+	"espec.":   "meaningType",
+	"fig.":     "meaningType",
+	"improp.":  "meaningType",
+	"per ext.": "meaningType",
+	"afec.":    "attitude",
+	"eufem.":   "attitude",
+	"iròn.":    "attitude",
+	"pej.":     "attitude",
+	"lit.":     "textType",
+	"cult.":    "socioCultural",
+	"fam.":     "socioCultural",
+	"infant.":  "socioCultural",
+	"pop.":     "socioCultural",
+	"dial.":    "geographic",
+}
+
+// teiNoteTypes maps the subsection letters to the types of the notes holding their
+// paragraphs. Etymologies are written as etym elements instead.
+var teiNoteTypes = map[string]string{
+	"a": "usage",
+	"b": "variants",
+	"c": "lexicalResources",
+	"d": "phraseology",
+	"f": "supplementary",
+}
+
+// teiRelationTypes maps the lexical relation kinds to cross-reference types. Kinds
+// without a TEI Lex-0 type are related words, with the kind as subtype.
+var teiRelationTypes = map[string]string{
+	RelationAntonym: "antonymy",
+	RelationRelated: "related",
+}
+
+type teiDocument struct {
+	XMLName xml.Name   `xml:"TEI"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Lang    string     `xml:"xml:lang,attr"`
+	Header  teiHeader  `xml:"teiHeader"`
+	Entries []teiEntry `xml:"text>body>entry"`
+}
+
+type teiHeader struct {
+	Title     string     `xml:"fileDesc>titleStmt>title"`
+	Publisher string     `xml:"fileDesc>publicationStmt>publisher"`
+	Licence   teiLicence `xml:"fileDesc>publicationStmt>availability>licence"`
+	Source    string     `xml:"fileDesc>sourceDesc>p"`
+}
+
+type teiLicence struct {
+	Target string `xml:"target,attr"`
+	Text   string `xml:",chardata"`
+}
+
+type teiEntry struct {
+	XMLName xml.Name `xml:"entry"`
+	ID      string   `xml:"xml:id,attr"`
+	Items   []any    // Forms, grammatical groups, senses, cross-references and notes, in order
+}
+
+type teiSense struct {
+	XMLName xml.Name `xml:"sense"`
+	ID      string   `xml:"xml:id,attr"`
+	N       string   `xml:"n,attr"`
+	Items   []any
+}
+
+type teiForm struct {
+	XMLName xml.Name `xml:"form"`
+	Type    string   `xml:"type,attr"`
+	Orth    string   `xml:"orth"`
+}
+
+type teiGramGrp struct {
+	XMLName xml.Name  `xml:"gramGrp"`
+	Grams   []teiGram `xml:"gram"`
+}
+
+type teiGram struct {
+	Type  string `xml:"type,attr"`
+	Norm  string `xml:"norm,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type teiUsage struct {
+	XMLName xml.Name `xml:"usg"`
+	Type    string   `xml:"type,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiDef struct {
+	XMLName xml.Name `xml:"def"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiXR struct {
+	XMLName xml.Name `xml:"xr"`
+	Type    string   `xml:"type,attr"`
+	Subtype string   `xml:"subtype,attr,omitempty"`
+	Items   []any    // References and their labels
+}
+
+type teiRef struct {
+	XMLName xml.Name `xml:"ref"`
+	Type    string   `xml:"type,attr,omitempty"`
+	Target  string   `xml:"target,attr,omitempty"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiLabel struct {
+	XMLName xml.Name `xml:"lbl"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiNote struct {
+	XMLName xml.Name `xml:"note"`
+	Type    string   `xml:"type,attr,omitempty"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiEtym struct {
+	XMLName xml.Name `xml:"etym"`
+	Value   string   `xml:",chardata"`
+}
+
+type teiCit struct {
+	XMLName xml.Name `xml:"cit"`
+	Type    string   `xml:"type,attr"`
+	Quote   string   `xml:"quote"`
+}
+
+// teiBuilder converts the entries to TEI Lex-0, resolving cross-references to the
+// ids of the entries.
+type teiBuilder struct {
+	ids              map[string]string // Entry slugs to xml:id values
+	entrySlugsByWord map[string]string
+}
+
+// TEIDocument returns the dictionary as a TEI Lex-0 document. Each entry is an entry
+// element with its lemma forms, grammatical category and numbered senses; the glosses
+// of the senses are definitions, register markers are usage labels, synonyms, lexical relations and links are
+// cross-references, and the "Ex.:" examples are citations. The rest of the
+// subsections are kept as typed notes, in plain text.
+func TEIDocument() ([]byte, error) {
+	b := &teiBuilder{ids: teiIDs(), entrySlugsByWord: buildEntrySlugsByWord()}
+	document := teiDocument{
+		Xmlns: teiNamespace,
+		Lang:  "ca",
+		Header: teiHeader{
+			Title:     "Diccionari de recursos lexicals (DIRELEX)",
+			Publisher: "Softcatalà",
+			Licence: teiLicence{
+				Target: "https://creativecommons.org/licenses/by-nc/4.0/",
+				Text:   "Copyright (c) 2025 Carles Castellanos i Llorenç, Agustí Mayor i Lloret. CC BY-NC 4.0.",
+			},
+			Source: "Diccionari de recursos lexicals, de Carles Castellanos i Llorenç i Agustí Mayor i Lloret.",
+		},
+	}
+	for _, entry := range AllEntries {
+		document.Entries = append(document.Entries, b.entry(entry))
+	}
+
+	content, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
+
+// teiIDs returns the xml:id of each entry: its slug, with the characters that are not
+// allowed in ids replaced by underscores and a numeric suffix if it is repeated.
+func teiIDs() map[string]string {
+	ids := make(map[string]string, len(AllEntries))
+	used := make(map[string]bool, len(AllEntries))
+	for _, entry := range AllEntries {
+		id := teiName(entry.Slug)
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s_%d", teiName(entry.Slug), n)
+		}
+		used[id] = true
+		ids[entry.Slug] = id
+	}
+	return ids
+}
+
+// teiName converts a string to an XML name without colons, as required by xml:id.
+func teiName(s string) string {
+	name := []rune(s)
+	for i, r := range name {
+		valid := unicode.IsLetter(r) || r == '_' ||
+			(i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.' || unicode.Is(unicode.Mn, r)))
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+func (b *teiBuilder) entry(entry Entry) teiEntry {
+	id := b.ids[entry.Slug]
+	result := teiEntry{ID: id}
+	for i, form := range strings.Split(plainText(entry.DisplayTitle), "|") {
+		formType := "lemma"
+		if i > 0 {
+			formType = "variant"
+		}
+		result.Items = append(result.Items, teiForm{Type: formType, Orth: strings.TrimSpace(form)})
+	}
+
+	// The grammatical category goes in the entry if all senses share it, or else in each sense.
+	shared := len(entry.Senses) > 0
+	for _, sense := range entry.Senses {
+		shared = shared && sense.PartOfSpeech == entry.Senses[0].PartOfSpeech
+	}
+	if shared {
+		result.Items = appendGramGrp(result.Items, entry.Senses[0].PartOfSpeech)
+	}
+
+	seen := make(map[string]int)
+	for _, sense := range entry.Senses {
+		senseID := id + "." + sense.Anchor()
+		seen[senseID]++
+		if seen[senseID] > 1 {
+			senseID += fmt.Sprintf("_%d", seen[senseID])
+		}
+		result.Items = append(result.Items, b.sense(sense, senseID, !shared))
+	}
+
+	for _, p := range entry.Notes {
+		result.Items = append(result.Items, b.paragraph(p, "")...)
+	}
+	return result
+}
+
+func (b *teiBuilder) sense(sense Sense, id string, withGramGrp bool) teiSense {
+	result := teiSense{ID: id, N: sense.Number}
+	if withGramGrp {
+		result.Items = appendGramGrp(result.Items, sense.PartOfSpeech)
+	}
+	if sense.Form != "" {
+		result.Items = append(result.Items, teiForm{Type: "variant", Orth: sense.Form})
+	}
+	for _, marker := range sense.Registers {
+		usageType, ok := teiUsageTypes[marker]
+		if !ok && strings.HasPrefix(marker, "cat. ") {
+			usageType = "geographic"
+		} else if !ok {
+			usageType = "hint"
+		}
+		result.Items = append(result.Items, teiUsage{Type: usageType, Value: marker})
+	}
+	if sense.Gloss != "" {
+		result.Items = append(result.Items, teiDef{Value: sense.Gloss})
+	}
+
+	if len(sense.Synonyms) > 0 {
+		xr := teiXR{Type: "synonymy"}
+		for _, word := range sense.Synonyms {
+			target := parseRelationTarget(strings.TrimRight(word, "!?"))
+			target.Slug = b.entrySlugsByWord[target.Word]
+			xr.Items = append(xr.Items, b.relationTarget(target)...)
+		}
+		result.Items = append(result.Items, xr)
+	}
+
+	for _, subsection := range sense.Subsections {
+		for _, p := range subsection.Paragraphs {
+			if subsection.Letter == "e" {
+				if text := plainText(p); text != "" {
+					result.Items = append(result.Items, teiEtym{Value: text})
+				}
+				continue
+			}
+			result.Items = append(result.Items, b.paragraph(p, teiNoteTypes[subsection.Letter])...)
+		}
+	}
+	return result
+}
+
+// appendGramGrp appends the grammatical group of a part of speech, if any.
+func appendGramGrp(items []any, partOfSpeech string) []any {
+	if partOfSpeech == "" {
+		return items
+	}
+	gram := teiGram{Type: "pos", Value: partOfSpeech}
+	gram.Norm = teiPartsOfSpeech[partOfSpeechPattern.FindString(partOfSpeech)]
+	return append(items, teiGramGrp{Grams: []teiGram{gram}})
+}
+
+// paragraph converts a paragraph to TEI: the lexical relations it lists, or a note
+// with its text followed by the citations of its examples and the cross-references
+// of its links.
+func (b *teiBuilder) paragraph(p, noteType string) []any {
+	if relation, ok := parseRelation(p, b.entrySlugsByWord); ok {
+		xr := teiXR{Type: teiRelationTypes[relation.Kind]}
+		if xr.Type == "" {
+			xr.Type, xr.Subtype = "related", relation.Kind
+		}
+		for _, target := range relation.Targets {
+			xr.Items = append(xr.Items, b.relationTarget(target)...)
+		}
+		return []any{xr}
+	}
+
+	var items []any
+	text, examples := plainText(p), ""
+	if loc := idiomExamplePattern.FindStringIndex(text); loc != nil {
+		text, examples = strings.TrimSpace(text[:loc[0]]), text[loc[1]:]
+	}
+	if text != "" {
+		items = append(items, teiNote{Type: noteType, Value: text})
+	}
+	for _, example := range strings.Split(examples, " / ") {
+		if example = strings.Trim(example, " —"); example != "" {
+			items = append(items, teiCit{Type: "example", Quote: example})
+		}
+	}
+
+	for _, m := range linkPattern.FindAllStringSubmatch(p, -1) {
+		href, label := html.UnescapeString(m[1]), plainText(m[2])
+		switch {
+		case strings.HasPrefix(href, "/lema/"):
+			slug, ok := resolveEntrySlug(strings.TrimPrefix(href, "/lema/"))
+			if !ok {
+				continue
+			}
+			items = append(items, teiXR{Type: "related", Items: []any{
+				teiRef{Type: "entry", Target: "#" + b.ids[slug], Value: label},
+			}})
+		case strings.HasPrefix(href, "/camp-semantic/"):
+			items = append(items, teiXR{Type: "related", Subtype: "semanticField", Items: []any{
+				teiRef{Target: href, Value: label},
+			}})
+		}
+	}
+	return items
+}
+
+// relationTarget returns the reference to a related word, followed by its note.
+func (b *teiBuilder) relationTarget(target RelationTarget) []any {
+	if target.Word == "" {
+		return nil
+	}
+	ref := teiRef{Type: "entry", Value: target.Word}
+	if target.Slug != "" {
+		ref.Target = "#" + b.ids[target.Slug]
+	}
+	items := []any{ref}
+	if target.Note != "" {
+		items = append(items, teiLabel{Value: target.Note})
+	}
+	return items
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTEIName(t *testing.T) {
+	tests := []struct{ slug, want string }{
+		{"aire", "aire"},
+		{"trist_|_trista", "trist___trista"},
+		{"l'aire", "l_aire"},
+		{"col·lecció", "col_lecció"},
+		{"1r", "_r"},
+		{"-ada", "_ada"},
+		{"a-b.c", "a-b.c"},
+	}
+	for _, tt := range tests {
+		if got := teiName(tt.slug); got != tt.want {
+			t.Errorf("teiName(%q) = %q; want %q", tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestTEIDocument(t *testing.T) {
+	withEntries(t, "aire", "vent", "l'aire", "l_aire")
+	AllEntries[0].Content = `<p>m.</p>` +
+		`<p><strong>1.</strong> [fam.: moviment] <a href="/lema/vent">vent</a>, ventada &amp; cop</p>` +
+		`<div><p>a) <span class="smallcaps">Explicacions d'ús</span></p>` +
+		`<p>El mot <a href="/lema/vent">vent</a> és general. Ex.: <em>Fa &lt;vent&gt;.</em> / <em>Bufa.</em></p></div>` +
+		`<div><p>c) <span class="smallcaps">Altres recursos lexicals</span></p>` +
+		`<p><strong>Ant</strong>.: calma (cult.), vent</p></div>` +
+		`<div><p>e) <span class="smallcaps">Etimologia</span></p><p>Del llatí <em>aer</em>.</p></div>` +
+		`<p><strong>2.</strong> [cat. val.] aparença</p>` +
+		`<p><strong>2.</strong> [arc.] cançó</p>`
+	for i := range AllEntries {
+		AllEntries[i].Senses, AllEntries[i].Notes = parseEntryContent(AllEntries[i].Content)
+	}
+
+	content, err := TEIDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The document is well-formed and its ids are unique.
+	ids := make(map[string]bool)
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed TEI document: %v\n%s", err, content)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "id" {
+					if ids[attr.Value] {
+						t.Errorf("repeated xml:id %q", attr.Value)
+					}
+					ids[attr.Value] = true
+				}
+			}
+		}
+	}
+
+	for _, want := range []string{
+		`<TEI xmlns="http://www.tei-c.org/ns/1.0" xml:lang="ca">`,
+		`<entry xml:id="aire">`,
+		`<entry xml:id="l_aire">`,
+		`<entry xml:id="l_aire_2">`,
+		`<gram type="pos" norm="NOUN">m.</gram>`,
+		`<sense xml:id="aire.1" n="1">`,
+		`<sense xml:id="aire.2_2" n="2">`,
+		`<usg type="socioCultural">fam.</usg>`,
+		`<def>moviment</def>`,
+		`<usg type="geographic">cat. val.</usg>`,
+		`<usg type="dating">arc.</usg>`,
+		`<ref type="entry" target="#vent">vent</ref>`,
+		`<ref type="entry">ventada &amp; cop</ref>`,
+		`<note type="usage">El mot vent és general.</note>`,
+		`<quote>Fa &lt;vent&gt;.</quote>`,
+		`<quote>Bufa.</quote>`,
+		`<xr type="antonymy">`,
+		`<lbl>cult.</lbl>`,
+		`<etym>Del llatí aer.</etym>`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("TEI document does not contain %s:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Widths of the terminal text of entries.
+const (
+	DefaultTerminalWidth = 80
+	MinTerminalWidth     = 40
+	MaxTerminalWidth     = 200
+)
+
+// Indents of the subsections of the terminal text of entries: headers are indented
+// below the sense, and paragraphs below the header text.
+const (
+	subsectionIndent  = "   "
+	subsectionContent = "      "
+)
+
+var (
+	ansiPattern      = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	senseHeadPattern = regexp.MustCompile(`^\s*(?:<strong>\s*)?(\d+)\s*(?:</strong>)?\s*(?:<strong>)?\s*\.?\s*(?:</strong>)?\s*`)
+)
+
+// TerminalOptions are the options of the terminal text of entries.
+type TerminalOptions struct {
+	Width   int    // Maximum line width, between MinTerminalWidth and MaxTerminalWidth
+	ANSI    bool   // Bold and italic as ANSI escape codes instead of *asterisks* and _underscores_
+	BaseURL string // Prefix of the site-relative links, e.g. "https://example.org"
+}
+
+// EntryTerminalText renders an entry as text for terminals, wrapped to the given
+// width: numbered senses with hanging indents, subsections indented below them, and
+// the targets of the links listed as numbered footnotes at the end.
+func EntryTerminalText(data EntryData, options TerminalOptions) string {
+	w := &documentWriter{format: formatTerminal, ansi: options.ANSI, baseURL: options.BaseURL}
+	width := min(max(options.Width, MinTerminalWidth), MaxTerminalWidth)
+	bold := func(s string) string {
+		if options.ANSI {
+			return ansiBold + s + ansiBoldEnd
+		}
+		return s
+	}
+
+	title := plainText(data.DisplayTitle)
+	blocks := []string{bold(title) + "\n" + strings.Repeat("=", utf8.RuneCountInString(title))}
+	for _, block := range splitBlocks(addSenseFragments(data.HTML)) {
+		switch block.Tag {
+		case "hr":
+			blocks = append(blocks, strings.Repeat("-", width))
+		case "div":
+			for _, p := range splitBlocks(block.Inner) {
+				if match := subsectionHeadPattern.FindStringSubmatchIndex(p.Inner); match != nil {
+					head := bold(p.Inner[match[2]:match[3]] + ")")
+					text := w.inlineText(p.Inner[match[1]:])
+					blocks = append(blocks, wrapText(head+" "+text, width, subsectionIndent, subsectionContent))
+				} else if text := w.inlineText(p.Inner); text != "" {
+					blocks = append(blocks, wrapText(text, width, subsectionContent, subsectionContent))
+				}
+			}
+		default:
+			if match := senseHeadPattern.FindStringSubmatchIndex(block.Inner); match != nil {
+				number := block.Inner[match[2]:match[3]] + "."
+				text := w.inlineText(block.Inner[match[1]:])
+				indent := strings.Repeat(" ", len(number)+1)
+				blocks = append(blocks, wrapText(bold(number)+" "+text, width, "", indent))
+			} else if text := w.inlineText(block.Inner); text != "" {
+				blocks = append(blocks, wrapText(text, width, "", ""))
+			}
+		}
+	}
+
+	if len(w.footnotes) > 0 {
+		var footnotes []string
+		for i, href := range w.footnotes {
+			footnotes = append(footnotes, fmt.Sprintf("[%d] %s", i+1, href))
+		}
+		blocks = append(blocks, strings.Join(footnotes, "\n"))
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// wrapText wraps text to the given width, not counting ANSI escape codes. The first
+// line starts with the first prefix and the rest with the indent; line breaks in the
+// text are kept.
+func wrapText(text string, width int, first, indent string) string {
+	var lines []string
+	prefix := first
+	for _, textLine := range strings.Split(text, "\n") {
+		line, lineWidth, empty := prefix, visibleWidth(prefix), true
+		for _, word := range strings.Fields(textLine) {
+			wordWidth := visibleWidth(word)
+			if !empty && lineWidth+1+wordWidth > width {
+				lines = append(lines, line)
+				line, lineWidth, empty = indent, visibleWidth(indent), true
+			}
+			if !empty {
+				line += " "
+				lineWidth++
+			}
+			line += word
+			lineWidth += wordWidth
+			empty = false
+		}
+		lines = append(lines, line)
+		prefix = indent
+	}
+	return strings.Join(lines, "\n")
+}
+
+// visibleWidth returns the number of characters of a string shown in terminals.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
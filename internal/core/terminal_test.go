@@ -0,0 +1,93 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text          string
+		width         int
+		first, indent string
+		want          string
+	}{
+		{"", 20, "", "", ""},
+		{"un dos tres", 20, "", "", "un dos tres"},
+		{"un dos tres quatre cinc", 10, "", "", "un dos\ntres\nquatre\ncinc"},
+		{"un dos tres quatre", 12, "1. ", "   ", "1. un dos\n   tres\n   quatre"},
+		{"línia\nnova", 20, "", "  ", "línia\n  nova"},
+		{"\x1b[1maire\x1b[0m és bo", 8, "", "", "\x1b[1maire\x1b[0m és\nbo"},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width, tt.first, tt.indent); got != tt.want {
+			t.Errorf("wrapText(%q, %d) = %q; want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+
+	// Words longer than the width are not broken.
+	long := strings.Repeat("a", 30)
+	if got := wrapText("b "+long, 10, "", ""); got != "b\n"+long {
+		t.Errorf("wrapText(long word) = %q", got)
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"aire", 4},
+		{"cafè", 4},
+		{"\x1b[3mcafè\x1b[0m", 4},
+	}
+	for _, tt := range tests {
+		if got := visibleWidth(tt.s); got != tt.want {
+			t.Errorf("visibleWidth(%q) = %d; want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestEntryTerminalText(t *testing.T) {
+	loadTestData(t)
+
+	data, _ := GetEntryData("aire")
+	got := EntryTerminalText(data, TerminalOptions{Width: 40, BaseURL: "https://example.org"})
+	want := `aire
+====
+
+m.
+
+1. vent[1], ventada, cop de vent
+
+   a) Explicacions d'ús
+
+      El mot _vent_ és la variant més
+      general. Ex.: _Este matí una
+      ventada se m'ha endut la roba._
+
+   f) Explicacions suplementàries
+
+      Vegeu vent[1] i estris[2].
+
+[1] https://example.org/lema/vent
+[2] https://example.org/camp-semantic/estris-de-cuina
+`
+	if got != want {
+		t.Errorf("EntryTerminalText(aire) =\n%s\nwant\n%s", got, want)
+	}
+
+	// Senses after a rule, with bold numbers and headers as ANSI escape codes.
+	data, _ = GetEntryData("vent")
+	got = EntryTerminalText(data, TerminalOptions{Width: 40, ANSI: true})
+	for _, part := range []string{
+		"\x1b[1m1.\x1b[22m [cat. val.] aire\n",
+		"\n   \x1b[1me)\x1b[22m Etimologia\n",
+		"\n" + strings.Repeat("-", 40) + "\n\nv. intr.\n\n\x1b[1m1.\x1b[22m bufar\n",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("EntryTerminalText(vent, ANSI) = %q; want it to contain %q", got, part)
+		}
+	}
+}
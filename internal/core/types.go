@@ -1,6 +1,9 @@
 package core
 
-import "html/template"
+import (
+	"html/template"
+	"strconv"
+)
 
 // Entry represents a dictionary entry with three forms of the title:
 //
@@ -12,6 +15,110 @@ type Entry struct {
 	DisplayTitle    string `json:"title_display"`
 	NormalizedTitle string `json:"title_normalized"`
 	Content         string `json:"content"`
+
+	// Senses holds the numbered senses parsed from Content at load time.
+	Senses []Sense `json:"-"`
+
+	// Notes holds the raw HTML of paragraphs outside any sense,
+	// such as "Vegeu ..." references in entries without senses of their own.
+	Notes []string `json:"-"`
+
+	// Relations holds the typed lexical relations (antonyms, derivatives, ...)
+	// extracted from the senses at load time.
+	Relations []Relation `json:"-"`
+}
+
+// Sense represents a numbered sense of an entry, parsed from its HTML content.
+type Sense struct {
+	// Number is the sense number as shown in the entry (e.g. "1").
+	Number string
+
+	// Block is the index of the grammatical block the sense belongs to.
+	// Blocks are separated by <hr> in the content and restart sense numbering.
+	Block int
+
+	// PartOfSpeech is the grammatical category of the block (e.g. "m.", "v. tr.").
+	PartOfSpeech string
+
+	// Form is the specific form the block applies to, if any (e.g. "acabar de").
+	Form string
+
+	// Registers are the usage markers of the sense (e.g. "cult.", "fig.").
+	Registers []string
+
+	// Gloss is the bracketed note of the sense header that is not a register marker.
+	Gloss string
+
+	// Synonyms are the alternative words listed in the sense header.
+	Synonyms []string
+
+	// HeaderHTML is the raw inner HTML of the sense header paragraph.
+	HeaderHTML string
+
+	// Subsections are the lettered subsections of the sense, in content order.
+	// Paragraphs found before any subsection header are kept in a subsection without letter.
+	Subsections []Subsection
+
+	offset int // Offset of the header paragraph in the entry content
+}
+
+// Anchor returns the id of the sense in entry pages (e.g. "1"). Senses of the second
+// and following grammatical blocks are prefixed with the block number (e.g. "2-1"),
+// as sense numbering restarts in each block.
+func (s Sense) Anchor() string {
+	if s.Block == 0 {
+		return s.Number
+	}
+	return strconv.Itoa(s.Block+1) + "-" + s.Number
+}
+
+// Subsection represents a lettered subsection of a sense (e.g. "a) Explicacions d'ús").
+type Subsection struct {
+	Letter     string
+	Title      string
+	Paragraphs []string // Raw inner HTML of each paragraph
+
+	offset int // Offset of the header paragraph in the entry content, -1 if there is no header
+}
+
+// EntryData is the structured representation of an entry, served by the JSON API.
+type EntryData struct {
+	Slug            string `json:"slug"`
+	DisplayTitle    string `json:"title"`
+	NormalizedTitle string `json:"normalized_title"`
+	URL             string `json:"url"`
+	PrevSlug        string `json:"prev_slug,omitempty"`
+	NextSlug        string `json:"next_slug,omitempty"`
+
+	// HTML is the raw HTML content of the entry, as in the data file.
+	HTML string `json:"html"`
+
+	// Notes are the raw HTML paragraphs outside any sense.
+	Notes []string `json:"notes,omitempty"`
+
+	Senses []SenseData `json:"senses"`
+}
+
+// SenseData is the structured representation of a sense (see Sense), in EntryData.
+type SenseData struct {
+	Number       string           `json:"number"`
+	Block        int              `json:"block"`
+	PartOfSpeech string           `json:"part_of_speech,omitempty"`
+	Form         string           `json:"form,omitempty"`
+	Registers    []string         `json:"registers,omitempty"`
+	Gloss        string           `json:"gloss,omitempty"`
+	Synonyms     []string         `json:"synonyms,omitempty"`
+	Anchor       string           `json:"anchor"`
+	HeaderHTML   string           `json:"header_html"`
+	Subsections  []SubsectionData `json:"subsections"`
+}
+
+// SubsectionData is the structured representation of a subsection (see Subsection), in SenseData.
+type SubsectionData struct {
+	Letter     string   `json:"letter,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Anchor     string   `json:"anchor"`
+	Paragraphs []string `json:"paragraphs"`
 }
 
 // SemanticField represents a semantic field page with a title, body content, and URL path.
@@ -21,6 +128,61 @@ type SemanticField struct {
 	Path  string `json:"path"`
 }
 
+// GlossaryTerm represents a term of the glossary, parsed from its HTML paragraph.
+type GlossaryTerm struct {
+	// ID is the anchor of the term in the glossary page (e.g. "abast-(donar-l'...)").
+	ID string
+
+	// Letter is the uppercase glossary letter the term is listed under.
+	Letter string
+
+	// Term is the plain text of the term (e.g. "abast (donar l'...)").
+	Term string
+
+	// Synonyms is the synonym phrase of the term, without the references
+	// (e.g. "arribar-hi").
+	Synonyms string
+
+	// Targets are the entries referenced by the term, in content order.
+	Targets []GlossaryTarget
+
+	// HTML is the original inner HTML of the glossary paragraph.
+	HTML template.HTML
+}
+
+// GlossaryTarget represents an entry referenced by a glossary term.
+type GlossaryTarget struct {
+	// Slug is the decoded slug of the referenced entry.
+	Slug string
+
+	// Title is the text of the link (e.g. "trist").
+	Title string
+
+	// Reference is the text written after the link (e.g. "1 i 2c", "v. tr. 1c CS", "Der").
+	Reference string
+
+	// PartOfSpeech is the grammatical category of the reference, if any (e.g. "v. tr.").
+	PartOfSpeech string
+
+	// Sense is the first sense of the reference, if any (e.g. "2c").
+	Sense string
+}
+
+// GlossaryTermView represents a glossary term with its targets expanded,
+// as shown in glossary term pages.
+type GlossaryTermView struct {
+	GlossaryTerm
+	ExpandedTargets []GlossaryTargetView
+}
+
+// GlossaryTargetView represents a glossary target with the text of the referenced sense.
+type GlossaryTargetView struct {
+	GlossaryTarget
+	EntryTitle template.HTML // Empty if the entry does not exist
+	Anchor     string        // Id of the referenced sense in the entry page, if it exists
+	SenseHTML  template.HTML // Empty if the reference does not match a sense
+}
+
 // Represents the data for rendering a page
 type PageData struct {
 	// PlainTextTitle is used for rendering the page title in the template,
@@ -34,8 +196,10 @@ type PageData struct {
 	Letters []string
 
 	// Used in entry pages
-	PrevSlug string
-	NextSlug string
+	PrevSlug  string
+	NextSlug  string
+	Backlinks Backlinks
+	Relations []RelationGroup
 
 	// Used in letter pages
 	Letter     string
@@ -43,9 +207,41 @@ type PageData struct {
 	NextLetter string
 	Entries    []LetterEntry
 
-	// Used in glossary page
-	GlossaryLetters []string
-	GlossaryContent map[string]template.HTML
+	// Used in glossary pages
+	GlossaryLetters       []string
+	GlossaryContent       map[string]template.HTML
+	GlossaryTerms         []GlossaryTerm
+	ExpandedGlossaryTerms []GlossaryTermView // Terms sharing the same id, in glossary term pages
+
+	// Used in idioms pages
+	IdiomLetters []string
+	Idioms       []Idiom
+	IdiomGroups  []IdiomGroup
+
+	// Used in synonym pages
+	Word          string
+	WordEntrySlug string // Slug of the entry for the word, if any
+	SynonymGroups []SynonymGroup
+
+	// Used in the 404 page of entries, for misspelled titles
+	Suggestions []TitleMatch
+
+	// Used in search pages
+	Query         string
+	TitleMatches  []TitleMatch // Entries with the query as title
+	SearchResults []TextSearchResult
+	Suffix        string      // Suffix pattern, in suffix searches
+	SuffixResults []WordMatch // Results of suffix searches
+	SearchTotal   int
+	Page          int
+	PrevPage      int    // 0 if there is no previous page
+	NextPage      int    // 0 if there is no next page
+	StaticSearch  bool   // Results are searched in the browser, in the static site
+	SearchError   string // Message for invalid queries, shown instead of the results
+
+	// Used in the redirect pages of the static site: the name of the script choosing
+	// the entry, in public/js (e.g. "random-entry")
+	RedirectScript string
 
 	// ContentHTML holds the main HTML content for dynamic pages
 	// (entry and semantic field pages)
@@ -57,3 +253,40 @@ type LetterEntry struct {
 	Slug         string
 	DisplayTitle template.HTML
 }
+
+// RelationGroup groups the lexical relations of an entry of the same kind, in entry pages.
+type RelationGroup struct {
+	Kind      string
+	Label     string
+	Relations []Relation
+}
+
+// Backlinks groups the pages linking to an entry by type of page.
+type Backlinks struct {
+	Entries        []Backlink
+	GlossaryTerms  []Backlink
+	SemanticFields []Backlink
+}
+
+// Backlink represents a page linking to an entry.
+type Backlink struct {
+	ID     string // Entry slug, glossary term id, or semantic field path
+	Title  template.HTML
+	Senses []string // Senses of the entry referenced by the links (e.g. "1", "1d")
+}
+
+// SearchItem is an entry in the data used by the entries search (js/data/terms.json).
+// Keys are kept short to reduce the size of the bundled data.
+type SearchItem struct {
+	Slug    string `json:"t"`
+	Display string `json:"d"` // Display title, as HTML
+	Search  string `json:"s"` // Normalized title
+}
+
+// GlossarySearchItem is a glossary term in the data used by the glossary search
+// (js/data/terms-glossary.json).
+type GlossarySearchItem struct {
+	ID      string `json:"id"`
+	Display string `json:"d"` // Term, as HTML
+	Search  string `json:"s"` // Normalized term
+}
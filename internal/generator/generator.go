@@ -3,15 +3,19 @@ package generator
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	brotli "github.com/molecule-man/go-brrr"
 	"github.com/softcatala/direlex/internal/core"
@@ -22,6 +26,13 @@ import (
 
 const (
 	OutputDir = "build"
+
+	// searchIndexDir is the directory of the static search index, read by js/search-text.js.
+	searchIndexDir = "index-cerca"
+
+	// searchParagraphsPerChunk is the number of paragraphs in each file of the static
+	// search index. js/search-text.js uses the same value.
+	searchParagraphsPerChunk = 500
 )
 
 var brotliWriterPool sync.Pool
@@ -64,12 +75,61 @@ func GenerateStaticSite() error {
 		return fmt.Errorf("failed to generate static pages: %w", err)
 	}
 
+	log.Printf("Generating %d glossary term pages...\n", len(core.GlossaryTerms))
+	err = generateGlossaryPages()
+	if err != nil {
+		return fmt.Errorf("failed to generate glossary pages: %w", err)
+	}
+
+	log.Printf("Generating idioms pages for %d idioms...\n", len(core.Idioms))
+	err = generateIdiomsPages()
+	if err != nil {
+		return fmt.Errorf("failed to generate idioms pages: %w", err)
+	}
+
 	log.Printf("Generating %d semantic field pages...\n", len(core.SemanticFields))
 	err = generateSemanticFieldPages()
 	if err != nil {
 		return fmt.Errorf("failed to generate semantic field pages: %w", err)
 	}
 
+	synonymWords := core.GetSynonymWords()
+	log.Printf("Generating %d synonym pages...\n", len(synonymWords))
+	err = generateSynonymPages(synonymWords)
+	if err != nil {
+		return fmt.Errorf("failed to generate synonym pages: %w", err)
+	}
+
+	log.Println("Generating search page...")
+	err = generateSearchPage()
+	if err != nil {
+		return fmt.Errorf("failed to generate search page: %w", err)
+	}
+
+	log.Println("Generating static search index...")
+	err = generateSearchIndex()
+	if err != nil {
+		return fmt.Errorf("failed to generate static search index: %w", err)
+	}
+
+	log.Println("Generating JSON API files...")
+	err = generateAPIFiles()
+	if err != nil {
+		return fmt.Errorf("failed to generate JSON API files: %w", err)
+	}
+
+	log.Println("Generating OpenSearch description and suggestions...")
+	err = generateOpenSearch()
+	if err != nil {
+		return fmt.Errorf("failed to generate OpenSearch files: %w", err)
+	}
+
+	log.Println("Generating word of the day schedule and feed...")
+	err = generateDailyEntry()
+	if err != nil {
+		return fmt.Errorf("failed to generate word of the day files: %w", err)
+	}
+
 	log.Println("Generating 404 page...")
 	err = generate404Page()
 	if err != nil {
@@ -162,6 +222,81 @@ func generateStaticPages() error {
 	return nil
 }
 
+// generateGlossaryPages generates the glossary letter and term pages as flat files.
+// Terms sharing the same id are rendered in a single page.
+func generateGlossaryPages() error {
+	for _, letter := range slices.Sorted(maps.Keys(core.Glossary)) {
+		terms := core.GetGlossaryTermsByLetter(letter)
+		if len(terms) == 0 {
+			continue
+		}
+
+		prevLetter, nextLetter := core.GetGlossaryNavigationLetters(letter)
+		pageData := core.CreateGlossaryLetterPageData(letter, terms, prevLetter, nextLetter)
+
+		outputPath := filepath.Join("glossari", "lletra", strings.ToLower(letter)+".html")
+		err := writeHTMLFile(outputPath, pageData)
+		if err != nil {
+			return fmt.Errorf("failed to generate glossary letter page %s: %w", letter, err)
+		}
+	}
+
+	generated := make(map[string]bool, len(core.GlossaryTerms))
+	for _, term := range core.GlossaryTerms {
+		if generated[term.ID] {
+			continue
+		}
+		generated[term.ID] = true
+		err := core.CheckGlossaryTermID(term.ID)
+		if err != nil {
+			// Reported by cmd/validate; the page would be written outside its directory
+			// or over the letter pages.
+			log.Printf("warning: skipped the page of glossary term %q: %v\n", term.ID, err)
+			continue
+		}
+
+		pageData := core.CreateGlossaryTermPageData(core.GetGlossaryTerms(term.ID))
+
+		// Ids containing slashes (e.g. "abreviació/abreviatura") are written in subdirectories,
+		// matching the request path served by Caddy.
+		outputPath := filepath.Join("glossari", term.ID+".html")
+		err = writeHTMLFile(outputPath, pageData)
+		if err != nil {
+			return fmt.Errorf("failed to generate glossary term page %s: %w", term.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// generateIdiomsPages generates the idioms index, letter and by-entry pages as flat
+// files, and the data used by the idioms search.
+func generateIdiomsPages() error {
+	err := writeHTMLFile("modismes.html", core.CreateIdiomsPageData())
+	if err != nil {
+		return fmt.Errorf("failed to generate idioms page: %w", err)
+	}
+
+	for _, letter := range core.GetIdiomLetters() {
+		idioms := core.GetIdiomsByLetter(letter)
+		prevLetter, nextLetter := core.GetIdiomNavigationLetters(letter)
+		pageData := core.CreateIdiomLetterPageData(letter, idioms, prevLetter, nextLetter)
+
+		outputPath := filepath.Join("modismes", "lletra", strings.ToLower(letter)+".html")
+		err := writeHTMLFile(outputPath, pageData)
+		if err != nil {
+			return fmt.Errorf("failed to generate idioms letter page %s: %w", letter, err)
+		}
+	}
+
+	err = writeHTMLFile(filepath.Join("modismes", "lemes.html"), core.CreateIdiomsByEntryPageData())
+	if err != nil {
+		return fmt.Errorf("failed to generate idioms by entry page: %w", err)
+	}
+
+	return writeJSONFile("modismes.json", core.GetIdiomSearchData())
+}
+
 // generateSemanticFieldPages generates all semantic field pages as flat files.
 func generateSemanticFieldPages() error {
 	for _, field := range core.SemanticFields {
@@ -177,6 +312,204 @@ func generateSemanticFieldPages() error {
 	return nil
 }
 
+// generateSynonymPages generates the pages of the words listed as synonyms or related
+// words as flat files. Spaces in words are written as underscores, as in entry slugs.
+func generateSynonymPages(words []string) error {
+	for _, word := range words {
+		if strings.Contains(word, "/") {
+			// Not a valid file name; the page is only served by the server.
+			continue
+		}
+		pageData := core.CreateSynonymPageData(core.GetSynonymSources(word))
+
+		outputPath := filepath.Join("sinonim", strings.ReplaceAll(word, " ", "_")+".html")
+		err := writeHTMLFile(outputPath, pageData)
+		if err != nil {
+			return fmt.Errorf("failed to generate synonym page %s: %w", word, err)
+		}
+	}
+
+	return nil
+}
+
+// generateSearchPage generates the full-text search page.
+func generateSearchPage() error {
+	// Results are searched in the browser, with the static search index.
+	pageData := core.CreateStaticSearchPageData()
+	return writeHTMLFile("cerca.html", pageData)
+}
+
+// generateSearchIndex generates the static search index, so that the static site
+// can search the entry contents without loading them all:
+//   - lemes.json: the entries, referenced by their index.
+//   - text/{shard}.json: the occurrences of the words of each shard (see core.TextIndexShard).
+//   - paragrafs/{n}.json: the text of the paragraphs, in chunks of searchParagraphsPerChunk.
+//   - sinonims/{shard}.json: the entries listing the words of each shard as synonyms or related words.
+//   - paraules.json: the titles and synonyms, for the suffix search.
+//
+// Browsers only fetch the shards of the searched words and the chunks of the shown results.
+func generateSearchIndex() error {
+	err := writeJSONFile(filepath.Join(searchIndexDir, "lemes.json"), core.GetSearchData())
+	if err != nil {
+		return err
+	}
+
+	for shard, words := range core.GetTextIndexShards() {
+		err := writeJSONFile(filepath.Join(searchIndexDir, "text", shard+".json"), words)
+		if err != nil {
+			return fmt.Errorf("failed to write text index shard %s: %w", shard, err)
+		}
+	}
+
+	paragraphs := core.GetTextIndexParagraphs()
+	for n, chunk := range slices.Collect(slices.Chunk(paragraphs, searchParagraphsPerChunk)) {
+		err := writeJSONFile(filepath.Join(searchIndexDir, "paragrafs", fmt.Sprintf("%d.json", n)), chunk)
+		if err != nil {
+			return fmt.Errorf("failed to write paragraphs chunk %d: %w", n, err)
+		}
+	}
+
+	for shard, words := range core.GetSynonymIndexShards() {
+		err := writeJSONFile(filepath.Join(searchIndexDir, "sinonims", shard+".json"), words)
+		if err != nil {
+			return fmt.Errorf("failed to write synonym index shard %s: %w", shard, err)
+		}
+	}
+
+	return writeJSONFile(filepath.Join(searchIndexDir, "paraules.json"), core.GetSuffixWords())
+}
+
+// generateAPIFiles generates the responses of the JSON API that do not depend on
+// query parameters, as JSON files with the paths of the API endpoints (e.g.
+// api/v1/lletra/a.json for /api/v1/lletra/a), so that the API also works on the
+// static site. The search endpoints are only served by the server.
+func generateAPIFiles() error {
+	apiDir := strings.TrimPrefix(core.APIPath, "/")
+
+	err := os.MkdirAll(filepath.Join(OutputDir, apiDir), 0o755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(OutputDir, apiDir, "openapi.json"), core.OpenAPIDocument, 0o644)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range core.AllEntries {
+		data, _ := core.GetEntryData(entry.Slug)
+		err := writeJSONFile(filepath.Join(apiDir, "lema", entry.Slug+".json"), data)
+		if err != nil {
+			return fmt.Errorf("failed to write entry %s: %w", entry.Slug, err)
+		}
+	}
+
+	err = writeJSONFile(filepath.Join(apiDir, "lletres.json"), core.GetLettersData())
+	if err != nil {
+		return err
+	}
+	for _, letter := range core.DictionaryLetters {
+		data, ok := core.GetLetterData(letter)
+		if !ok {
+			continue
+		}
+		err := writeJSONFile(filepath.Join(apiDir, "lletra", letter+".json"), data)
+		if err != nil {
+			return fmt.Errorf("failed to write letter %s: %w", letter, err)
+		}
+	}
+
+	err = writeJSONFile(filepath.Join(apiDir, "camps-semantics.json"), core.GetSemanticFieldsData())
+	if err != nil {
+		return err
+	}
+	for _, field := range core.SemanticFields {
+		data, _ := core.GetSemanticFieldData(field.Path)
+		err := writeJSONFile(filepath.Join(apiDir, "camp-semantic", field.Path+".json"), data)
+		if err != nil {
+			return fmt.Errorf("failed to write semantic field %s: %w", field.Path, err)
+		}
+	}
+
+	glossary := core.GetGlossaryLettersData()
+	err = writeJSONFile(filepath.Join(apiDir, "glossari.json"), glossary)
+	if err != nil {
+		return err
+	}
+	for _, letter := range glossary.Letters {
+		data, _ := core.GetGlossaryLetterData(letter.Letter)
+		err := writeJSONFile(filepath.Join(apiDir, "glossari", strings.ToLower(letter.Letter)+".json"), data)
+		if err != nil {
+			return fmt.Errorf("failed to write glossary letter %s: %w", letter.Letter, err)
+		}
+	}
+
+	return nil
+}
+
+// generateOpenSearch generates the OpenSearch description (opensearch.xml) and the
+// title suggestions of the prefixes of all titles (suggeriments/{prefix}.json), which
+// replace the suggestions API of the server. URLs start with the SITE_URL env variable,
+// as browsers need absolute URLs.
+func generateOpenSearch() error {
+	siteURL := core.GetSiteURL()
+	if siteURL == "" {
+		log.Println("warning: SITE_URL is not set, the OpenSearch description will have relative URLs")
+	}
+	content, err := core.OpenSearchDescription(siteURL, "/suggeriments/{searchTerms}.json")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(OutputDir, "opensearch.xml"), content, 0o644)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range core.GetTitleSuggestionPrefixes() {
+		if strings.Contains(prefix, "/") || strings.HasPrefix(prefix, ".") {
+			// Not a valid file name; the suggestions are only served by the server.
+			continue
+		}
+		err := writeJSONFile(filepath.Join("suggeriments", prefix+".json"), core.GetTitleSuggestions(prefix))
+		if err != nil {
+			return fmt.Errorf("failed to write suggestions for %q: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
+
+// generateDailyEntry generates the word of the day schedule in the JSON API
+// (api/v1/lema-del-dia.json) and its Atom feed (lema-del-dia.xml), from the generation
+// date. The feed only lists past days, so the site must be generated daily to keep it
+// current. The pages at /atzar and /lema-del-dia redirect in the browser, as the server
+// does, with the search data and the schedule.
+func generateDailyEntry() error {
+	today := time.Now()
+	err := writeJSONFile(filepath.Join(strings.TrimPrefix(core.APIPath, "/"), "lema-del-dia.json"), core.GetDailySchedule(today))
+	if err != nil {
+		return err
+	}
+
+	err = writeHTMLFile("atzar.html", core.CreateRedirectPageData("Lema a l'atzar", "random-entry"))
+	if err != nil {
+		return err
+	}
+	err = writeHTMLFile("lema-del-dia.html", core.CreateRedirectPageData("Lema del dia", "daily-entry"))
+	if err != nil {
+		return err
+	}
+
+	siteURL := core.GetSiteURL()
+	if siteURL == "" {
+		log.Println("warning: SITE_URL is not set, the word of the day feed will have relative URLs")
+	}
+	content, err := core.DailyEntryFeed(siteURL, today)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(OutputDir, "lema-del-dia.xml"), content, 0o644)
+}
+
 // generate404Page generates the 404 error page.
 func generate404Page() error {
 	pageData := core.Create404PageData()
@@ -217,6 +550,22 @@ func writeHTMLFile(relativePath string, data core.PageData) error {
 	return nil
 }
 
+// writeJSONFile writes data encoded as JSON to the output directory.
+func writeJSONFile(relativePath string, data any) error {
+	fullPath := filepath.Join(OutputDir, relativePath)
+	err := os.MkdirAll(filepath.Dir(fullPath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fullPath, content, 0o644)
+}
+
 // compressFiles compresses files using GZIP and Brotli in parallel.
 func compressFiles() error {
 	filesToCompress, err := getFilesToCompress()
@@ -290,7 +639,7 @@ func compressFile(path string) error {
 func shouldCompress(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
-	case ".css", ".js", ".svg", ".html":
+	case ".css", ".js", ".svg", ".html", ".json", ".xml":
 		return true
 	default:
 		return false
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+// Paging defaults of the JSON API.
+const (
+	defaultPerPage  = 20
+	wordListPerPage = 100 // Default of the word lists of suffix search pages
+	maxPerPage      = 100
+)
+
+// searchResponse is the JSON response of the search APIs.
+type searchResponse[T any] struct {
+	Query   string `json:"query"`
+	Total   int    `json:"total"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Results []T    `json:"results"`
+
+	// Suggestions are the entries with the closest titles to a query without results.
+	Suggestions []core.TitleMatch `json:"suggestions,omitempty"`
+}
+
+// errorResponse is the JSON response of the API for invalid requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// SearchAPIHandler handles requests for searching entries by title, or titles and
+// synonyms by suffix.
+// Behavior:
+//   - Matches the "q" parameter against normalized titles, ignoring case and accents.
+//   - Returns exact matches first, then prefix and substring matches (see core.SearchTitles).
+//   - With the "acaba" parameter instead, returns the titles and synonyms ending with it,
+//     where "?" and "*" are wildcards (see core.SearchSuffix).
+//   - Pages the results with the "page" (from 1) and "per_page" parameters.
+//   - Suggests the entries with the closest titles if there are no results (see core.SuggestEntries).
+//   - Serves a 400 JSON error for a missing query, a suffix pattern over the limits of
+//     core.SearchSuffix or invalid paging parameters.
+func SearchAPIHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	suffix := r.URL.Query().Get("acaba")
+	if query == "" && suffix == "" {
+		serveJSONError(w, http.StatusBadRequest, `missing "q" or "acaba" parameter`)
+		return
+	}
+	if query != "" && suffix != "" {
+		serveJSONError(w, http.StatusBadRequest, `"q" and "acaba" parameters cannot be combined`)
+		return
+	}
+	page, perPage, ok := parsePaging(r, defaultPerPage)
+	if !ok {
+		serveJSONError(w, http.StatusBadRequest, `invalid "page" or "per_page" parameter`)
+		return
+	}
+
+	if suffix != "" {
+		matches, err := core.SearchSuffix(suffix)
+		if err != nil {
+			serveJSONError(w, http.StatusBadRequest, `invalid "acaba" parameter: `+err.Error())
+			return
+		}
+		serveJSON(w, searchResponse[core.WordMatch]{
+			Query:   suffix,
+			Total:   len(matches),
+			Page:    page,
+			PerPage: perPage,
+			Results: paginate(matches, page, perPage),
+		})
+		return
+	}
+
+	matches := core.SearchTitles(query)
+	response := searchResponse[core.TitleMatch]{
+		Query:   query,
+		Total:   len(matches),
+		Page:    page,
+		PerPage: perPage,
+		Results: paginate(matches, page, perPage),
+	}
+	if len(matches) == 0 {
+		response.Suggestions = core.SuggestEntries(query)
+	}
+	serveJSON(w, response)
+}
+
+// TextSearchAPIHandler handles requests for searching the text of the entries.
+// Behavior:
+//   - Matches the words and quoted phrases of the "q" parameter against the entry contents
+//     (see core.SearchText).
+//   - Returns the matching entries with the matching paragraphs, their sense and subsection,
+//     and highlighted snippets.
+//   - Pages the results with the "page" (from 1) and "per_page" parameters.
+//   - Serves a 400 JSON error for a missing query or invalid paging parameters.
+func TextSearchAPIHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		serveJSONError(w, http.StatusBadRequest, `missing "q" parameter`)
+		return
+	}
+	page, perPage, ok := parsePaging(r, defaultPerPage)
+	if !ok {
+		serveJSONError(w, http.StatusBadRequest, `invalid "page" or "per_page" parameter`)
+		return
+	}
+
+	results := core.SearchText(query)
+	serveJSON(w, searchResponse[core.TextSearchResult]{
+		Query:   query,
+		Total:   len(results),
+		Page:    page,
+		PerPage: perPage,
+		Results: paginate(results, page, perPage),
+	})
+}
+
+// EntryAPIHandler handles requests for an entry as structured JSON, at /api/lema/{slug}.
+// Behavior:
+//   - Returns the titles of the entry, its previous and next entries, its raw HTML
+//     content, and its senses and subsections (see core.EntryData).
+//   - Serves a 404 JSON error for non-existent entries.
+func EntryAPIHandler(w http.ResponseWriter, r *http.Request) {
+	entry, ok := core.GetEntryData(r.PathValue("slug"))
+	if !ok {
+		serveJSONError(w, http.StatusNotFound, "entry not found")
+		return
+	}
+
+	serveJSON(w, entry)
+}
+
+// LettersAPIHandler handles requests for the letters of the dictionary, with their
+// number of entries (see core.GetLettersData).
+func LettersAPIHandler(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, core.GetLettersData())
+}
+
+// LetterAPIHandler handles requests for the entries starting with a letter, at
+// /api/v1/lletra/{letter}, in Catalan collation order (see core.GetLetterData).
+// Serves a 404 JSON error for letters without entries.
+func LetterAPIHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := core.GetLetterData(r.PathValue("letter"))
+	if !ok {
+		serveJSONError(w, http.StatusNotFound, "letter not found")
+		return
+	}
+
+	serveJSON(w, data)
+}
+
+// SemanticFieldsAPIHandler handles requests for the list of semantic fields.
+func SemanticFieldsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, core.GetSemanticFieldsData())
+}
+
+// SemanticFieldAPIHandler handles requests for a semantic field, at
+// /api/v1/camp-semantic/{slug}. Serves a 404 JSON error for non-existent semantic fields.
+func SemanticFieldAPIHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := core.GetSemanticFieldData(r.PathValue("slug"))
+	if !ok {
+		serveJSONError(w, http.StatusNotFound, "semantic field not found")
+		return
+	}
+
+	serveJSON(w, data)
+}
+
+// GlossaryAPIHandler handles requests for the letters of the glossary, with their
+// number of terms (see core.GetGlossaryLettersData).
+func GlossaryAPIHandler(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, core.GetGlossaryLettersData())
+}
+
+// GlossaryLetterAPIHandler handles requests for the glossary terms of a letter, at
+// /api/v1/glossari/{letter}, with the entries they reference (see core.GetGlossaryLetterData).
+// Serves a 404 JSON error for letters without glossary terms.
+func GlossaryLetterAPIHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := core.GetGlossaryLetterData(r.PathValue("letter"))
+	if !ok {
+		serveJSONError(w, http.StatusNotFound, "letter not found")
+		return
+	}
+
+	serveJSON(w, data)
+}
+
+// DailyScheduleAPIHandler handles requests for the words of the day, from 30 days ago
+// to a year ahead (see core.GetDailySchedule), so that other sites can show the word
+// of the day of their local date.
+func DailyScheduleAPIHandler(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, core.GetDailySchedule(time.Now()))
+}
+
+// OpenAPIHandler serves the OpenAPI description of the JSON API, embedded in the binary.
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(core.OpenAPIDocument)
+	if err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// WithCORS wraps an API handler to allow requests from any origin, as the API is
+// public and read-only.
+func WithCORS(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		handler(w, r)
+	}
+}
+
+// synonymResponse is the JSON response of the synonym API.
+type synonymResponse struct {
+	Word    string               `json:"word"`
+	Sources []core.SynonymSource `json:"sources"`
+}
+
+// SynonymAPIHandler handles requests for the senses listing a word as a synonym or
+// related word, at /api/sinonim/{word}.
+// Behavior:
+//   - Matches the word ignoring case and accents; spaces may be written as underscores.
+//   - Returns the senses listing the word, in dictionary order, with the kind of
+//     reference ("synonym", or a relation kind such as "related" or "antonym").
+//   - Serves a 404 JSON error for words not listed in any entry.
+func SynonymAPIHandler(w http.ResponseWriter, r *http.Request) {
+	word := r.PathValue("word")
+	sources := core.GetSynonymSources(word)
+	if len(sources) == 0 {
+		serveJSONError(w, http.StatusNotFound, "word not found")
+		return
+	}
+
+	serveJSON(w, synonymResponse{Word: word, Sources: sources})
+}
+
+// SuggestionsAPIHandler handles OpenSearch suggestion requests from browsers.
+// Behavior:
+//   - Returns the titles of the entries matching the "q" parameter (see core.GetTitleSuggestions),
+//     in the application/x-suggestions+json format: ["query", ["title", ...]].
+//   - Returns no titles for a missing query.
+func SuggestionsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-suggestions+json")
+	err := json.NewEncoder(w).Encode(core.GetTitleSuggestions(r.URL.Query().Get("q")))
+	if err != nil {
+		log.Printf("Error encoding JSON: %v", err)
+	}
+}
+
+// parsePaging returns the "page" and "per_page" parameters of a request, or their
+// defaults if they are missing: 1 and defaultSize. It reports false if they are not valid.
+func parsePaging(r *http.Request, defaultSize int) (int, int, bool) {
+	page, perPage := 1, defaultSize
+	if value := r.URL.Query().Get("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, 0, false
+		}
+		page = n
+	}
+	if value := r.URL.Query().Get("per_page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPerPage {
+			return 0, 0, false
+		}
+		perPage = n
+	}
+	return page, perPage, true
+}
+
+// paginate returns the items of the given page. Pages past the end are empty, and
+// are checked before computing the offset, which would overflow for huge pages.
+func paginate[T any](items []T, page, perPage int) []T {
+	if page-1 >= (len(items)+perPage-1)/perPage {
+		return []T{}
+	}
+	start := (page - 1) * perPage
+	end := min(start+perPage, len(items))
+	return append([]T{}, items[start:end]...)
+}
+
+// serveJSON writes a value as a JSON response.
+func serveJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.Printf("Error encoding JSON: %v", err)
+	}
+}
+
+// serveJSONError writes a JSON error response with the given status code.
+func serveJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(errorResponse{Error: message})
+	if err != nil {
+		log.Printf("Error encoding JSON: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParsePaging(t *testing.T) {
+	tests := []struct {
+		query         string
+		page, perPage int
+		ok            bool
+	}{
+		{"", 1, 20, true},
+		{"page=3", 3, 20, true},
+		{"page=2&per_page=5", 2, 5, true},
+		{"per_page=" + strconv.Itoa(maxPerPage), 1, maxPerPage, true},
+		{"page=" + strconv.Itoa(math.MaxInt), math.MaxInt, 20, true},
+		{"page=0", 0, 0, false},
+		{"page=-1", 0, 0, false},
+		{"page=x", 0, 0, false},
+		{"page=99999999999999999999", 0, 0, false},
+		{"per_page=0", 0, 0, false},
+		{"per_page=" + strconv.Itoa(maxPerPage+1), 0, 0, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/cerca?"+tt.query, nil)
+		page, perPage, ok := parsePaging(r, 20)
+		if page != tt.page || perPage != tt.perPage || ok != tt.ok {
+			t.Errorf("parsePaging(%q) = %d, %d, %v; want %d, %d, %v",
+				tt.query, page, perPage, ok, tt.page, tt.perPage, tt.ok)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		page, perPage int
+		want          []int
+	}{
+		{1, 2, []int{1, 2}},
+		{2, 2, []int{3, 4}},
+		{3, 2, []int{5}},
+		{4, 2, []int{}},
+		{1, 10, []int{1, 2, 3, 4, 5}},
+		{2, 5, []int{}},
+		{4611686018427387905, 2, []int{}},
+		{math.MaxInt, maxPerPage, []int{}},
+	}
+	for _, tt := range tests {
+		got := paginate(items, tt.page, tt.perPage)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paginate(page %d, per page %d) = %v; want %v", tt.page, tt.perPage, got, tt.want)
+		}
+	}
+
+	if got := paginate([]int{}, 1, 10); got == nil || len(got) != 0 {
+		t.Errorf("paginate(empty) = %#v; want an empty, non-nil slice", got)
+	}
+}
+
+func TestAPIHandlers(t *testing.T) {
+	loadTestData(t)
+
+	tests := []struct {
+		handler    http.HandlerFunc
+		target     string
+		name       string // Path value name, if any
+		value      string
+		wantStatus int
+		wantError  string
+	}{
+		{EntryAPIHandler, "/api/v1/lema/abril", "slug", "abril", http.StatusOK, ""},
+		{EntryAPIHandler, "/api/v1/lema/xyz", "slug", "xyz", http.StatusNotFound, "entry not found"},
+		{LetterAPIHandler, "/api/v1/lletra/a", "letter", "a", http.StatusOK, ""},
+		{LetterAPIHandler, "/api/v1/lletra/z", "letter", "z", http.StatusNotFound, "letter not found"},
+		{LetterAPIHandler, "/api/v1/lletra/ab", "letter", "ab", http.StatusNotFound, "letter not found"},
+		{SemanticFieldAPIHandler, "/api/v1/camp-semantic/estris-de-cuina", "slug", "estris-de-cuina", http.StatusOK, ""},
+		{SemanticFieldAPIHandler, "/api/v1/camp-semantic/xyz", "slug", "xyz", http.StatusNotFound, "semantic field not found"},
+		{GlossaryLetterAPIHandler, "/api/v1/glossari/a", "letter", "a", http.StatusOK, ""},
+		{GlossaryLetterAPIHandler, "/api/v1/glossari/b", "letter", "b", http.StatusNotFound, "letter not found"},
+		{SynonymAPIHandler, "/api/v1/sinonim/cangueli", "word", "cangueli", http.StatusOK, ""},
+		{SynonymAPIHandler, "/api/v1/sinonim/xyz", "word", "xyz", http.StatusNotFound, "word not found"},
+		{SearchAPIHandler, "/api/v1/cerca", "", "", http.StatusBadRequest, `missing "q" or "acaba" parameter`},
+		{SearchAPIHandler, "/api/v1/cerca?q=abril&page=0", "", "", http.StatusBadRequest, `invalid "page" or "per_page" parameter`},
+		{TextSearchAPIHandler, "/api/v1/cerca/text", "", "", http.StatusBadRequest, `missing "q" parameter`},
+	}
+	for _, tt := range tests {
+		w := serve(tt.handler, tt.target, tt.name, tt.value)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d; want %d", tt.target, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type %q; want application/json", tt.target, got)
+		}
+		var response errorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Errorf("%s: invalid JSON: %v", tt.target, err)
+		}
+		if response.Error != tt.wantError {
+			t.Errorf("%s: error %q; want %q", tt.target, response.Error, tt.wantError)
+		}
+	}
+}
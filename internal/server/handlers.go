@@ -3,6 +3,8 @@ package server
 import (
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/softcatala/direlex/internal/core"
 )
@@ -24,6 +26,11 @@ func BasicPageHandler(path, title string) http.HandlerFunc {
 //
 // Additionally:
 //   - Serves a 404 page for non-root paths, or non-existent entries.
+//   - Suggests the entries with the closest titles in the 404 page of non-existent entries.
+//   - Serves entries as JSON, Markdown or plain text depending on the Accept header or
+//     the "format" parameter (see negotiateFormat), and a 406 error if none is acceptable.
+//   - Plain text is wrapped for terminals (see core.EntryTerminalText), and is the default
+//     for curl and Wget (see isTerminalClient and terminalOptions).
 func IndexAndEntryHandler(w http.ResponseWriter, r *http.Request) {
 	slug := r.PathValue("slug")
 	if slug == "" {
@@ -41,10 +48,50 @@ func IndexAndEntryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if acceptsAnyFormat(r) {
+		w.Header().Set("Vary", "Accept, User-Agent")
+	} else {
+		w.Header().Set("Vary", "Accept")
+	}
+	format, ok := negotiateFormat(r)
+	if !ok {
+		serveNotAcceptable(w)
+		return
+	}
+	if isTerminalClient(r) {
+		format = formatText
+	}
+	if format != formatHTML {
+		data, ok := core.GetEntryData(slug)
+		if !ok {
+			serveFormatError(w, format, http.StatusNotFound, "entry not found")
+			return
+		}
+		document := func(format string) string {
+			return core.EntryDocument(data, format)
+		}
+		if format == formatText {
+			options, ok := terminalOptions(r)
+			if !ok {
+				serveFormatError(w, format, http.StatusBadRequest, `invalid "width" or "ansi" parameter`)
+				return
+			}
+			document = func(string) string {
+				return core.EntryTerminalText(data, options)
+			}
+		}
+		serveFormat(w, format, data, document)
+		return
+	}
+
 	// Entry page
 	entryHTML, ok := core.RenderEntryBySlug(slug)
 	if !ok {
-		serveNotFound(w)
+		w.WriteHeader(http.StatusNotFound)
+		err := core.MainTemplate.Execute(w, core.CreateEntryNotFoundPageData(slug))
+		if err != nil {
+			log.Printf("Error executing template: %v", err)
+		}
 		return
 	}
 
@@ -62,14 +109,39 @@ func IndexAndEntryHandler(w http.ResponseWriter, r *http.Request) {
 //
 // Additionally:
 //   - Serves a 404 page for invalid letters or letters with no entries.
-//   - Does not sort lemes, as this should be sorted using the Catalan locale on export time.
+//   - Does not sort lemes, as they are sorted in Catalan collation order at load time.
+//   - Serves the list as JSON, Markdown or plain text depending on the Accept header or
+//     the "format" parameter (see negotiateFormat), and a 406 error if none is acceptable.
 func LetterHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Vary", "Accept")
+	format, ok := negotiateFormat(r)
+	if !ok {
+		serveNotAcceptable(w)
+		return
+	}
+
 	letter := r.PathValue("letter")
 	if len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		if format != formatHTML {
+			serveFormatError(w, format, http.StatusNotFound, "letter not found")
+			return
+		}
 		serveNotFound(w)
 		return
 	}
 
+	if format != formatHTML {
+		data, ok := core.GetLetterData(letter)
+		if !ok {
+			serveFormatError(w, format, http.StatusNotFound, "letter not found")
+			return
+		}
+		serveFormat(w, format, data, func(format string) string {
+			return core.LetterDocument(data, format)
+		})
+		return
+	}
+
 	entries := core.GetEntriesByFirstLetter(letter)
 	if len(entries) == 0 {
 		serveNotFound(w)
@@ -90,8 +162,29 @@ func LetterHandler(w http.ResponseWriter, r *http.Request) {
 //
 // Additionally:
 //   - Serves a 404 page for non-existent semantic fields.
+//   - Serves the field as JSON, Markdown or plain text depending on the Accept header or
+//     the "format" parameter (see negotiateFormat), and a 406 error if none is acceptable.
 func SemanticFieldHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Vary", "Accept")
+	format, ok := negotiateFormat(r)
+	if !ok {
+		serveNotAcceptable(w)
+		return
+	}
+
 	slug := r.PathValue("slug")
+	if format != formatHTML {
+		data, ok := core.GetSemanticFieldData(slug)
+		if !ok {
+			serveFormatError(w, format, http.StatusNotFound, "semantic field not found")
+			return
+		}
+		serveFormat(w, format, data, func(format string) string {
+			return core.SemanticFieldDocument(data, format)
+		})
+		return
+	}
+
 	for _, field := range core.SemanticFields {
 		if field.Path == slug {
 			pageData := core.CreateSemanticFieldPageData(field.Title, field.Body)
@@ -106,6 +199,249 @@ func SemanticFieldHandler(w http.ResponseWriter, r *http.Request) {
 	serveNotFound(w)
 }
 
+// GlossaryTermHandler handles requests for glossary term pages.
+// It expects a URL path in the format /glossari/{term...}, where {term} is the id of the term
+// in the glossary (ids may contain slashes, e.g. "abreviació/abreviatura").
+// It renders all the terms sharing that id, with the senses referenced by their targets.
+//
+// Additionally:
+//   - Serves a 404 page for non-existent terms.
+func GlossaryTermHandler(w http.ResponseWriter, r *http.Request) {
+	terms := core.GetGlossaryTerms(r.PathValue("term"))
+	if len(terms) == 0 {
+		serveNotFound(w)
+		return
+	}
+
+	pageData := core.CreateGlossaryTermPageData(terms)
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// GlossaryLetterHandler handles requests for browsing glossary terms by the first letter.
+// It expects a URL path in the format /glossari/lletra/{letter}, where {letter} is a single lowercase letter.
+//
+// Additionally:
+//   - Serves a 404 page for letters without glossary terms.
+func GlossaryLetterHandler(w http.ResponseWriter, r *http.Request) {
+	letter := strings.ToUpper(r.PathValue("letter"))
+	terms := core.GetGlossaryTermsByLetter(letter)
+	if len(terms) == 0 {
+		serveNotFound(w)
+		return
+	}
+
+	prevLetter, nextLetter := core.GetGlossaryNavigationLetters(letter)
+	pageData := core.CreateGlossaryLetterPageData(letter, terms, prevLetter, nextLetter)
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// IdiomsHandler handles requests for the idioms index page, which links to the
+// idioms letter pages and includes the client-side idioms search.
+func IdiomsHandler(w http.ResponseWriter, r *http.Request) {
+	pageData := core.CreateIdiomsPageData()
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// IdiomLetterHandler handles requests for browsing idioms by the first letter.
+// It expects a URL path in the format /modismes/lletra/{letter}, where {letter} is a single lowercase letter.
+//
+// Additionally:
+//   - Serves a 404 page for letters without idioms.
+func IdiomLetterHandler(w http.ResponseWriter, r *http.Request) {
+	letter := strings.ToUpper(r.PathValue("letter"))
+	idioms := core.GetIdiomsByLetter(letter)
+	if len(idioms) == 0 {
+		serveNotFound(w)
+		return
+	}
+
+	prevLetter, nextLetter := core.GetIdiomNavigationLetters(letter)
+	pageData := core.CreateIdiomLetterPageData(letter, idioms, prevLetter, nextLetter)
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// IdiomsByEntryHandler handles requests for the page listing the idioms grouped by entry.
+func IdiomsByEntryHandler(w http.ResponseWriter, r *http.Request) {
+	pageData := core.CreateIdiomsByEntryPageData()
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// IdiomsDataHandler serves the data used by the client-side idioms search as JSON.
+// The static generator writes the same data to modismes.json.
+func IdiomsDataHandler(w http.ResponseWriter, r *http.Request) {
+	serveJSON(w, core.GetIdiomSearchData())
+}
+
+// SynonymHandler handles requests for the page of a word listed as a synonym or related word.
+// It expects a URL path in the format /sinonim/{word}, where spaces in the word may be
+// written as underscores, and lists the senses of the entries listing that word.
+//
+// Additionally:
+//   - Serves a 404 page for words not listed in any entry.
+func SynonymHandler(w http.ResponseWriter, r *http.Request) {
+	sources := core.GetSynonymSources(r.PathValue("word"))
+	if len(sources) == 0 {
+		serveNotFound(w)
+		return
+	}
+
+	pageData := core.CreateSynonymPageData(sources)
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// TextSearchHandler handles requests for the full-text search page.
+// Behavior:
+//   - Shows the search forms if the "q" and "acaba" parameters are empty.
+//   - Lists the entries containing the words and quoted phrases of the "q" parameter, with
+//     highlighted snippets (see core.SearchText), paged with the "page" parameter.
+//   - Lists the titles and synonyms ending with the pattern of the "acaba" parameter
+//     (see core.SearchSuffix), paged with the "page" parameter.
+//   - Serves a 404 page for pages past the end of the results, and the search page with
+//     the limits and a 400 status for suffix patterns over the limits of core.SearchSuffix.
+func TextSearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	suffix := strings.TrimSpace(r.URL.Query().Get("acaba"))
+	perPageDefault := defaultPerPage
+	if suffix != "" && query == "" {
+		perPageDefault = wordListPerPage
+	}
+	page, perPage, ok := parsePaging(r, perPageDefault)
+	if !ok {
+		serveNotFound(w)
+		return
+	}
+
+	var pageData core.PageData
+	if suffix != "" && query == "" {
+		results, err := core.SearchSuffix(suffix)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			err = core.MainTemplate.Execute(w, core.CreateSuffixSearchErrorPageData(suffix))
+			if err != nil {
+				log.Printf("Error executing template: %v", err)
+			}
+			return
+		}
+		prevPage, nextPage, ok := pageLinks(len(results), page, perPage)
+		if !ok {
+			serveNotFound(w)
+			return
+		}
+		pageData = core.CreateSuffixSearchPageData(suffix, paginate(results, page, perPage), len(results), page, prevPage, nextPage)
+	} else {
+		results := core.SearchText(query)
+		prevPage, nextPage, ok := pageLinks(len(results), page, perPage)
+		if !ok {
+			serveNotFound(w)
+			return
+		}
+		pageData = core.CreateSearchPageData(query, paginate(results, page, perPage), len(results), page, prevPage, nextPage)
+	}
+
+	err := core.MainTemplate.Execute(w, pageData)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// pageLinks returns the previous and next pages of a page of results, or 0 if there
+// are none. It reports false for pages past the end of the results.
+func pageLinks(total, page, perPage int) (int, int, bool) {
+	pageCount := (total + perPage - 1) / perPage
+	if page > max(pageCount, 1) {
+		return 0, 0, false
+	}
+
+	var prevPage, nextPage int
+	if page > 1 {
+		prevPage = page - 1
+	}
+	if page < pageCount {
+		nextPage = page + 1
+	}
+	return prevPage, nextPage, true
+}
+
+// OpenSearchHandler serves the OpenSearch description of the site, so that browsers
+// can add it as a search engine with title suggestions from /api/suggeriments.
+// URLs are absolute: they start with the SITE_URL env variable if set, or with
+// the scheme and host of the request otherwise.
+// The static generator writes the same description to opensearch.xml.
+func OpenSearchHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := core.OpenSearchDescription(siteURL(r), "/api/suggeriments?q={searchTerms}")
+	if err != nil {
+		log.Printf("Error encoding OpenSearch description: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/opensearchdescription+xml")
+	_, err = w.Write(content)
+	if err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// RandomEntryHandler redirects to an entry chosen at random.
+func RandomEntryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, core.EntryPath(core.GetRandomEntry().Slug), http.StatusFound)
+}
+
+// DailyEntryHandler redirects to the word of the day, which changes at midnight UTC
+// (see core.GetDailyEntry).
+func DailyEntryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, core.EntryPath(core.GetDailyEntry(time.Now()).Slug), http.StatusFound)
+}
+
+// DailyEntryFeedHandler serves the Atom feed of the words of the day of the last 30 days.
+// URLs are absolute, as in OpenSearchHandler.
+// The static generator writes the same feed to lema-del-dia.xml.
+func DailyEntryFeedHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := core.DailyEntryFeed(siteURL(r), time.Now())
+	if err != nil {
+		log.Printf("Error encoding word of the day feed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml")
+	_, err = w.Write(content)
+	if err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// siteURL returns the public URL of the site: the SITE_URL env variable if set, or
+// else the scheme and host of the request.
+func siteURL(r *http.Request) string {
+	if siteURL := core.GetSiteURL(); siteURL != "" {
+		return siteURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
 // serveNotFound renders a standard 404 Not Found error page.
 func serveNotFound(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNotFound)
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+// loadTestData loads the test data of the core package and parses the templates.
+func loadTestData(t *testing.T) {
+	t.Helper()
+	content, err := os.ReadFile("../core/testdata/data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "data.json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := gzip.NewWriter(file)
+	_, err = w.Write(content)
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = core.LoadDataFromFile(path)
+	if err == nil {
+		err = core.ParseTemplates()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serve returns the response of a handler to a GET request, with the given path values.
+func serve(handler http.HandlerFunc, target string, pathValues ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(pathValues); i += 2 {
+		r.SetPathValue(pathValues[i], pathValues[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestTextSearchHandler(t *testing.T) {
+	loadTestData(t)
+
+	tests := []struct {
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/cerca?acaba=ril", http.StatusOK, `<a href="/lema/abril">abril</a>`},
+		{"/cerca?acaba=a?re", http.StatusOK, `<a href="/lema/aire">aire</a>`},
+		{"/cerca?acaba=xyz", http.StatusOK, "Cap paraula no acaba en «xyz»."},
+		{"/cerca?acaba=" + strings.Repeat("a", 51), http.StatusBadRequest, "La terminació pot tenir com a màxim 50 caràcters i 10 comodins"},
+		{"/cerca?acaba=" + strings.Repeat("a?", 11), http.StatusBadRequest, "La terminació pot tenir com a màxim 50 caràcters i 10 comodins"},
+		{"/cerca?acaba=ril&page=2", http.StatusNotFound, "404: No s'ha trobat"},
+		{"/cerca?q=primavera", http.StatusOK, `<a href="/lema/abril">abril</a>`},
+	}
+	for _, tt := range tests {
+		w := serve(TextSearchHandler, tt.target)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d; want %d", tt.target, w.Code, tt.wantStatus)
+		}
+		if !strings.Contains(w.Body.String(), tt.wantBody) {
+			t.Errorf("%s: body does not contain %q", tt.target, tt.wantBody)
+		}
+	}
+}
+
+func TestIndexAndEntryHandlerVary(t *testing.T) {
+	loadTestData(t)
+
+	tests := []struct {
+		userAgent, accept, query string
+		wantVary                 string
+		wantContentType          string
+	}{
+		{"Mozilla/5.0", "text/html,*/*;q=0.8", "", "Accept", "text/html"},
+		{"Mozilla/5.0", "*/*", "", "Accept, User-Agent", "text/html"},
+		{"curl/8.5.0", "*/*", "", "Accept, User-Agent", "text/plain"},
+		{"curl/8.5.0", "application/json", "", "Accept", "application/json"},
+		{"curl/8.5.0", "*/*", "format=md", "Accept", "text/markdown"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/lema/abril?"+tt.query, nil)
+		r.SetPathValue("slug", "abril")
+		r.Header.Set("User-Agent", tt.userAgent)
+		r.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		IndexAndEntryHandler(w, r)
+		if got := w.Header().Get("Vary"); got != tt.wantVary {
+			t.Errorf("%s, %s, %q: Vary %q; want %q", tt.userAgent, tt.accept, tt.query, got, tt.wantVary)
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantContentType) {
+			t.Errorf("%s, %s, %q: Content-Type %q; want %s", tt.userAgent, tt.accept, tt.query, got, tt.wantContentType)
+		}
+	}
+}
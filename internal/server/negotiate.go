@@ -0,0 +1,202 @@
+package server
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/softcatala/direlex/internal/core"
+)
+
+// Formats of the pages that support content negotiation.
+const (
+	formatHTML     = "html"
+	formatJSON     = "json"
+	formatMarkdown = core.FormatMarkdown
+	formatText     = core.FormatText
+)
+
+// formatMediaTypes maps the media types of the Accept header to formats, in order of
+// preference when several are equally acceptable (e.g. "*/*").
+var formatMediaTypes = []struct {
+	mediaType string
+	format    string
+}{
+	{"text/html", formatHTML},
+	{"application/xhtml+xml", formatHTML},
+	{"application/json", formatJSON},
+	{"text/markdown", formatMarkdown},
+	{"text/plain", formatText},
+}
+
+// formatContentTypes are the Content-Type headers of the text formats.
+var formatContentTypes = map[string]string{
+	formatMarkdown: "text/markdown; charset=utf-8",
+	formatText:     "text/plain; charset=utf-8",
+}
+
+// mediaRange is a media range of the Accept header, with its quality value.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateFormat returns the format of the response to a page request: the "format"
+// parameter if present ("html", "json", "text" or "txt", "markdown" or "md"), or
+// else the preferred format of the Accept header. Requests without an Accept header
+// get HTML. It reports false if no format is acceptable.
+func negotiateFormat(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		switch strings.ToLower(format) {
+		case "html":
+			return formatHTML, true
+		case "json":
+			return formatJSON, true
+		case "text", "txt":
+			return formatText, true
+		case "markdown", "md":
+			return formatMarkdown, true
+		default:
+			return "", false
+		}
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formatHTML, true
+	}
+	ranges := parseAccept(accept)
+	format, best := "", 0.0
+	for _, t := range formatMediaTypes {
+		if quality := acceptQuality(ranges, t.mediaType); quality > best {
+			format, best = t.format, quality
+		}
+	}
+	return format, format != ""
+}
+
+// parseAccept parses the media ranges of an Accept header. Parameters other than
+// the quality value are ignored, and invalid quality values count as 1.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, quality})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality value of a media type: the one of the most
+// specific matching range (e.g. "text/plain" over "text/*" over "*/*"), or 0.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	quality, specificity := 0.0, -1
+	group, _, _ := strings.Cut(mediaType, "/")
+	for _, r := range ranges {
+		s := -1
+		switch r.mediaType {
+		case mediaType:
+			s = 2
+		case group + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality
+}
+
+// isTerminalClient reports whether the request comes from curl or Wget without
+// asking for a specific format, in which case entries are served as terminal text.
+func isTerminalClient(r *http.Request) bool {
+	userAgent := r.UserAgent()
+	if !strings.HasPrefix(userAgent, "curl/") && !strings.HasPrefix(userAgent, "Wget/") {
+		return false
+	}
+	return acceptsAnyFormat(r)
+}
+
+// acceptsAnyFormat reports whether a request does not ask for a specific format, with
+// neither a "format" parameter nor an Accept header other than "*/*". Only the format
+// of these requests depends on the User-Agent header (see isTerminalClient).
+func acceptsAnyFormat(r *http.Request) bool {
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	return r.URL.Query().Get("format") == "" && (accept == "" || accept == "*/*")
+}
+
+// terminalOptions returns the options of the terminal text of entries: the "width"
+// parameter (80 by default), and the "ansi" parameter ("1" or "0") to use ANSI escape
+// codes for emphasis, by default only with curl, whose output usually goes to a
+// terminal. It reports false if a parameter is invalid.
+func terminalOptions(r *http.Request) (core.TerminalOptions, bool) {
+	options := core.TerminalOptions{
+		Width:   core.DefaultTerminalWidth,
+		ANSI:    strings.HasPrefix(r.UserAgent(), "curl/"),
+		BaseURL: siteURL(r),
+	}
+	if value := r.URL.Query().Get("width"); value != "" {
+		width, err := strconv.Atoi(value)
+		if err != nil || width < core.MinTerminalWidth || width > core.MaxTerminalWidth {
+			return options, false
+		}
+		options.Width = width
+	}
+	if value := r.URL.Query().Get("ansi"); value != "" {
+		ansi, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, false
+		}
+		options.ANSI = ansi
+	}
+	return options, true
+}
+
+// serveFormat writes data as JSON, or the document rendered from it as Markdown or
+// plain text, depending on the format.
+func serveFormat(w http.ResponseWriter, format string, data any, document func(format string) string) {
+	if format == formatJSON {
+		serveJSON(w, data)
+		return
+	}
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	_, err := io.WriteString(w, document(format))
+	if err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// serveFormatError writes an error response in a format other than HTML.
+func serveFormatError(w http.ResponseWriter, format string, status int, message string) {
+	if format == formatJSON {
+		serveJSONError(w, status, message)
+		return
+	}
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.WriteHeader(status)
+	_, err := io.WriteString(w, message+"\n")
+	if err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// serveNotAcceptable writes the response to requests without an acceptable format.
+func serveNotAcceptable(w http.ResponseWriter) {
+	http.Error(w, "406 not acceptable: available formats are html, json, text and markdown", http.StatusNotAcceptable)
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		query, accept string
+		format        string
+		ok            bool
+	}{
+		{"", "", formatHTML, true},
+		{"", "  ", formatHTML, true},
+		{"", "*/*", formatHTML, true},
+		{"", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", formatHTML, true},
+		{"", "application/json", formatJSON, true},
+		{"", "text/markdown", formatMarkdown, true},
+		{"", "text/plain", formatText, true},
+		{"", "text/*", formatHTML, true},
+		{"", "text/*;q=0.5, text/plain", formatText, true},
+		{"", "text/html;q=0.1, application/json;q=0.9", formatJSON, true},
+		{"", "TEXT/PLAIN; Q=0.5", formatText, true},
+		{"", "text/html;q=0, application/xhtml+xml;q=0, */*", formatJSON, true}, // Most specific range wins
+		{"", "application/json;q=invalid, text/html;q=0.5", formatJSON, true},
+		{"", "image/png", "", false},
+		{"", "text/html;q=0", "", false},
+		{"format=json", "text/html", formatJSON, true},
+		{"format=JSON", "", formatJSON, true},
+		{"format=txt", "", formatText, true},
+		{"format=md", "", formatMarkdown, true},
+		{"format=html", "application/json", formatHTML, true},
+		{"format=pdf", "", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/lema/aire?"+tt.query, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		format, ok := negotiateFormat(r)
+		if format != tt.format || ok != tt.ok {
+			t.Errorf("negotiateFormat(%q, Accept %q) = %q, %v; want %q, %v", tt.query, tt.accept, format, ok, tt.format, tt.ok)
+		}
+	}
+}
+
+func TestAcceptQuality(t *testing.T) {
+	ranges := parseAccept("text/*;q=0.3, text/plain;q=0.7, */*;q=0.1, ;q=1")
+	tests := []struct {
+		mediaType string
+		want      float64
+	}{
+		{"text/plain", 0.7},
+		{"text/html", 0.3},
+		{"application/json", 0.1},
+	}
+	for _, tt := range tests {
+		if got := acceptQuality(ranges, tt.mediaType); got != tt.want {
+			t.Errorf("acceptQuality(%q) = %v; want %v", tt.mediaType, got, tt.want)
+		}
+	}
+	if got := acceptQuality(parseAccept("text/html"), "application/json"); got != 0 {
+		t.Errorf("acceptQuality(no match) = %v; want 0", got)
+	}
+}
+
+func TestIsTerminalClient(t *testing.T) {
+	tests := []struct {
+		userAgent, accept, query string
+		want                     bool
+	}{
+		{"curl/8.5.0", "*/*", "", true},
+		{"curl/8.5.0", "", "", true},
+		{"Wget/1.21.4", "*/*", "", true},
+		{"curl/8.5.0", "application/json", "", false},
+		{"curl/8.5.0", "*/*", "format=md", false},
+		{"Mozilla/5.0", "*/*", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/lema/aire?"+tt.query, nil)
+		r.Header.Set("User-Agent", tt.userAgent)
+		r.Header.Set("Accept", tt.accept)
+		if got := isTerminalClient(r); got != tt.want {
+			t.Errorf("isTerminalClient(%q, %q, %q) = %v; want %v", tt.userAgent, tt.accept, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalOptions(t *testing.T) {
+	tests := []struct {
+		userAgent, query string
+		width            int
+		ansi, ok         bool
+	}{
+		{"curl/8.5.0", "", 80, true, true},
+		{"Wget/1.21.4", "", 80, false, true},
+		{"curl/8.5.0", "width=120&ansi=0", 120, false, true},
+		{"Wget/1.21.4", "ansi=1", 80, true, true},
+		{"curl/8.5.0", "width=39", 0, false, false},
+		{"curl/8.5.0", "width=201", 0, false, false},
+		{"curl/8.5.0", "width=abc", 0, false, false},
+		{"curl/8.5.0", "ansi=maybe", 0, false, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/lema/aire?"+tt.query, nil)
+		r.Header.Set("User-Agent", tt.userAgent)
+		options, ok := terminalOptions(r)
+		if ok != tt.ok || (ok && (options.Width != tt.width || options.ANSI != tt.ansi)) {
+			t.Errorf("terminalOptions(%q, %q) = %+v, %v; want width %d, ANSI %v, %v",
+				tt.userAgent, tt.query, options, ok, tt.width, tt.ansi, tt.ok)
+		}
+	}
+}
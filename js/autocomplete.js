@@ -19,9 +19,17 @@
  */
 
 export function normalizeText(text) {
-  // Match backend normalization: only remove specific Catalan accents.
+  // Match backend normalization (catalan.Normalize): only remove specific Catalan accents.
   // Leave ç unchanged (unlike NFD normalization which converts ç to c).
+  // Typographic apostrophes and hyphens, and l.l or ŀl, are written in their canonical form.
   return text
+    .replace(/[’‘ʼ´`]/g, "'")
+    .replace(/[‐‑−]/g, "-")
+    .replace(/\u00ad/g, "")
+    .replace(/ŀl/g, "l·l")
+    .replace(/Ŀl/g, "L·l")
+    .replace(/ĿL/g, "L·L")
+    .replace(/(l)[.•‧∙⋅](l)/gi, "$1·$2")
     .replace(/[à]/g, "a")
     .replace(/[èé]/g, "e")
     .replace(/[íï]/g, "i")
@@ -29,6 +37,25 @@ export function normalizeText(text) {
     .replace(/[úü]/g, "u");
 }
 
+export function entryPath(slug) {
+  // Match Go template's URL encoding behavior:
+  // - Encode: ( ) ' (encodeURIComponent leaves these unescaped)
+  // - Decode: [ ] : (encodeURIComponent encodes these but Go doesn't)
+  // CMS content links may use PHP urlencode output (encodes [ ] :), which still resolves.
+  // Current data slugs include only these reserved characters: [ ] : ( ) ' |.
+  // If slugs ever include other reserved URL characters, keep Go/JS encoders in sync.
+  // Note: Hex digits case differs (JS: uppercase, Go template: lowercase)
+  // but this is functionally equivalent per RFC 3986.
+  const encoded = encodeURIComponent(slug)
+    .replaceAll("(", "%28")
+    .replaceAll(")", "%29")
+    .replaceAll("'", "%27")
+    .replaceAll("%5B", "[")
+    .replaceAll("%5D", "]")
+    .replaceAll("%3A", ":");
+  return `/lema/${encoded}`;
+}
+
 export function createAutocomplete(options) {
   const {
     inputElement,
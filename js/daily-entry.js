@@ -0,0 +1,18 @@
+// Static site version of the /lema-del-dia redirect of the server. The schedule is
+// generated with the site, and lists the words of the day by UTC date.
+const today = new Date().toISOString().slice(0, 10);
+const message = document.getElementById("redirect-message");
+
+fetch("/api/v1/lema-del-dia.json")
+  .then((response) => response.json())
+  .then((schedule) => {
+    const day = schedule.days.find((day) => day.date === today);
+    if (day) {
+      window.location.replace(day.url);
+    } else {
+      message.textContent = "El lema del dia d'avui encara no està disponible.";
+    }
+  })
+  .catch(() => {
+    message.textContent = "No s'ha pogut obrir el lema del dia.";
+  });
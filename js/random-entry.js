@@ -0,0 +1,6 @@
+import { entryPath } from "./autocomplete.js";
+import terms from "./data/terms.json" with { type: "json" };
+
+// Static site version of the /atzar redirect of the server.
+const term = terms[Math.floor(Math.random() * terms.length)];
+window.location.replace(entryPath(term.t));
@@ -0,0 +1,31 @@
+import { createAutocomplete, entryPath } from "./autocomplete.js";
+
+const searchInput = document.querySelector('input[type="search"]');
+const isMobile = /Android|iPad|iPhone/i.test(navigator.userAgent);
+
+// Idioms are extracted from the entries when loading the data, so they are
+// fetched from the server (or the generated site) instead of being bundled.
+fetch("/modismes.json")
+  .then((response) => response.json())
+  .then((idioms) => {
+    const autocompleteInstance = createAutocomplete({
+      containerElement: document.querySelector(".search-form"),
+      inputElement: searchInput,
+      data: idioms,
+      displayKey: "d",
+      searchKey: "s",
+      titleKey: "s",
+      maxResults: 20,
+      onSelect: (idiom) => {
+        searchInput.value = "";
+        window.location.href = `${entryPath(idiom.t)}#${idiom.a}`;
+      },
+      enableTextSelect: isMobile,
+    });
+
+    window.addEventListener("pageshow", () => {
+      // Ensure browser does not try to remember last form value.
+      searchInput.value = "";
+      autocompleteInstance.close();
+    });
+  });
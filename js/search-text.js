@@ -0,0 +1,409 @@
+import { entryPath, normalizeText } from "./autocomplete.js";
+
+// Full-text search of the static site, with the static search index written by
+// the generator (see generateSearchIndex). It matches the search of the server
+// (core.SearchText): entries must contain every word or quoted phrase of the
+// query, and entries with more matching paragraphs come first.
+//
+// Only the index shards of the searched words, and the paragraphs of the shown
+// results, are fetched.
+//
+// The search of words ending with a suffix pattern (core.SearchSuffix) uses the
+// list of all titles and synonyms instead.
+
+const INDEX_PATH = "/index-cerca";
+const PARAGRAPHS_PER_CHUNK = 500; // Same as searchParagraphsPerChunk in the generator
+const PER_PAGE = 20;
+const WORD_LIST_PER_PAGE = 100;
+const SNIPPET_LENGTH = 240;
+const MAX_SUFFIX_PATTERN_LENGTH = 50; // Same as core.MaxSuffixPatternLength
+const MAX_SUFFIX_WILDCARDS = 10; // Same as core.MaxSuffixWildcards
+const SUFFIX_LIMITS_MESSAGE = `La terminació pot tenir com a màxim ${MAX_SUFFIX_PATTERN_LENGTH} caràcters i ${MAX_SUFFIX_WILDCARDS} comodins («?» o «*»).`;
+
+// Words are sequences of letters and digits, including the middle dot of "l·l" (see core.tokenize).
+const WORD_PATTERN = /[\p{L}\p{Nd}]+(?:·(?=\p{L})[\p{L}\p{Nd}]*)*/gu;
+
+const searchInput = document.querySelector('.text-search input[name="q"]');
+const suffixInput = document.querySelector('.text-search input[name="acaba"]');
+const resultsElement = document.querySelector(".text-search-results");
+
+const fetched = new Map();
+
+function fetchJSON(path) {
+  if (!fetched.has(path)) {
+    fetched.set(
+      path,
+      fetch(path).then((response) => (response.ok ? response.json() : {})),
+    );
+  }
+  return fetched.get(path);
+}
+
+function normalize(text) {
+  return normalizeText(text.toLowerCase());
+}
+
+// shard returns the shard of a normalized word, as core.TextIndexShard.
+function shard(word) {
+  const letters = [...word].filter((c) => /[\p{L}\p{Nd}]/u.test(c));
+  return letters.slice(0, 2).join("") || "_";
+}
+
+function tokenize(text) {
+  return [...text.matchAll(WORD_PATTERN)].map((match) => ({
+    word: normalize(match[0]),
+    start: match.index,
+    end: match.index + match[0].length,
+  }));
+}
+
+// parseQuery splits a query into its clauses, each a sequence of words that must
+// appear consecutively, as core.parseTextQuery.
+function parseQuery(query) {
+  const clauses = [];
+  const addClause = (text) => {
+    const words = tokenize(normalizeText(text)).map((token) => token.word);
+    if (words.length > 0) {
+      clauses.push(words);
+    }
+  };
+
+  query
+    .replace(/[«»“”]/g, '"')
+    .split('"')
+    .forEach((part, i) => {
+      if (i % 2 === 1) {
+        addClause(part);
+      } else {
+        part.split(/\s+/).forEach(addClause);
+      }
+    });
+  return clauses;
+}
+
+// postings returns the occurrences of a word as [entry, paragraph, position] triples.
+async function postings(word) {
+  const words = await fetchJSON(
+    `${INDEX_PATH}/text/${encodeURIComponent(shard(word))}.json`,
+  );
+  const flat = words[word] || [];
+  const result = [];
+  for (let i = 0; i < flat.length; i += 3) {
+    result.push(flat.slice(i, i + 3));
+  }
+  return result;
+}
+
+// findPhrase returns the occurrences of the first word of a phrase followed by
+// the rest of the words of the phrase.
+async function findPhrase(words) {
+  const occurrences = await Promise.all(words.map(postings));
+  let matches = occurrences[0];
+  occurrences.slice(1).forEach((next, offset) => {
+    const positions = new Set(next.map(([, p, position]) => `${p}:${position}`));
+    matches = matches.filter(([, p, position]) =>
+      positions.has(`${p}:${position + offset + 1}`),
+    );
+  });
+  return matches;
+}
+
+// search returns the matching entries, each with the positions of the matched
+// words in its paragraphs.
+async function search(clauses) {
+  const highlights = new Map(); // Paragraph => positions
+  const entries = new Map(); // Entry => { paragraphs, clauses }
+  const matches = await Promise.all(clauses.map(findPhrase));
+  matches.forEach((occurrences, c) => {
+    for (const [entry, paragraph, position] of occurrences) {
+      if (!highlights.has(paragraph)) {
+        highlights.set(paragraph, new Set());
+      }
+      clauses[c].forEach((_, k) => highlights.get(paragraph).add(position + k));
+      if (!entries.has(entry)) {
+        entries.set(entry, { entry, paragraphs: new Set(), clauses: new Set() });
+      }
+      entries.get(entry).paragraphs.add(paragraph);
+      entries.get(entry).clauses.add(c);
+    }
+  });
+
+  return [...entries.values()]
+    .filter((result) => result.clauses.size === clauses.length)
+    .sort(
+      (a, b) => b.paragraphs.size - a.paragraphs.size || a.entry - b.entry,
+    )
+    .map((result) => ({
+      entry: result.entry,
+      paragraphs: [...result.paragraphs]
+        .sort((a, b) => a - b)
+        .map((paragraph) => ({ paragraph, positions: highlights.get(paragraph) })),
+    }));
+}
+
+async function paragraph(n) {
+  const chunk = await fetchJSON(
+    `${INDEX_PATH}/paragrafs/${Math.floor(n / PARAGRAPHS_PER_CHUNK)}.json`,
+  );
+  return chunk[n % PARAGRAPHS_PER_CHUNK];
+}
+
+function escapeHTML(text) {
+  return text
+    .replaceAll("&", "&amp;")
+    .replaceAll("<", "&lt;")
+    .replaceAll(">", "&gt;")
+    .replaceAll('"', "&#34;")
+    .replaceAll("'", "&#39;");
+}
+
+// renderSnippet returns the HTML of a paragraph text with the words at the given
+// positions highlighted, cut around the first one if it is long (see core.renderSnippet).
+function renderSnippet(text, positions) {
+  const tokens = tokenize(text);
+  let first = text.length;
+  for (const position of positions) {
+    if (position < tokens.length) {
+      first = Math.min(first, tokens[position].start);
+    }
+  }
+
+  let start = 0;
+  let end = text.length;
+  if (text.length > SNIPPET_LENGTH) {
+    start = Math.max(0, first - SNIPPET_LENGTH / 4);
+    end = Math.min(text.length, start + SNIPPET_LENGTH);
+    const space = text.slice(start, first).indexOf(" ");
+    if (start > 0 && space >= 0) {
+      start += space + 1;
+    }
+    const lastSpace = text.slice(first, end).lastIndexOf(" ");
+    if (end < text.length && lastSpace >= 0) {
+      end = first + lastSpace;
+    }
+  }
+
+  let html = start > 0 ? "… " : "";
+  let last = start;
+  tokens.forEach((token, position) => {
+    if (!positions.has(position) || token.start < start || token.end > end) {
+      return;
+    }
+    html += escapeHTML(text.slice(last, token.start));
+    html += `<mark>${escapeHTML(text.slice(token.start, token.end))}</mark>`;
+    last = token.end;
+  });
+  html += escapeHTML(text.slice(last, end));
+  return end < text.length ? `${html} …` : html;
+}
+
+async function renderResult(entries, result) {
+  const entry = entries[result.entry];
+  const url = entryPath(entry.t);
+  const matches = await Promise.all(
+    result.paragraphs.map(async ({ paragraph: n, positions }) => {
+      const p = await paragraph(n);
+      let location = "";
+      if (p.a) {
+        const label = [
+          p.p ? `${escapeHTML(p.p)} ` : "",
+          escapeHTML(p.n || ""),
+          p.x ? ` ${escapeHTML(p.x)}) ` : "",
+          p.h ? `<span class="no-bold">${escapeHTML(p.h)}</span>` : "",
+        ].join("");
+        location = `<a class="senses" href="${url}#${encodeURIComponent(p.a)}">${label}</a> `;
+      }
+      return `<p>${location}${renderSnippet(p.t, positions)}</p>`;
+    }),
+  );
+  return `<article class="text-result"><h3><a href="${url}">${entry.d}</a></h3>${matches.join("")}</article>`;
+}
+
+// titleForms returns the forms of a normalized title, as core.titleForms.
+function titleForms(title) {
+  const forms = [title];
+  for (let form of title.split("|")) {
+    form = form.trim();
+    forms.push(form);
+    const i = form.search(/[([]/);
+    if (i > 0) {
+      forms.push(form.slice(0, i).trim());
+    }
+  }
+  return forms;
+}
+
+// renderTitleMatches returns links to the entries with the query as title.
+function renderTitleMatches(entries, query) {
+  const title = normalize(query.trim().replaceAll("_", " "));
+  const links = entries
+    .filter((entry) => titleForms(entry.s).includes(title))
+    .map((entry) => `<a href="${entryPath(entry.t)}">${entry.d}</a>`);
+  return links.length > 0
+    ? `<p class="title-matches">Lema: ${links.join(", ")}</p>`
+    : "";
+}
+
+// renderSynonyms returns a link to the synonym page of a query that is a single
+// word listed as a synonym or related word in some entry.
+async function renderSynonyms(query) {
+  const word = normalize(query.trim().replaceAll("_", " "));
+  const words = await fetchJSON(
+    `${INDEX_PATH}/sinonims/${encodeURIComponent(shard(word))}.json`,
+  );
+  const sources = words[word];
+  if (!sources || word.includes("/")) {
+    return "";
+  }
+  const slugs = new Set(sources.map((source) => source.slug));
+  const href = `/sinonim/${encodeURIComponent(sources[0].word.replaceAll(" ", "_"))}`;
+  return `<p>«${escapeHTML(sources[0].word)}» apareix com a sinònim o mot relacionat a ${slugs.size} ${slugs.size === 1 ? "lema" : "lemes"}: <a href="${href}">vegeu on</a>.</p>`;
+}
+
+// renderPagination returns the links to the previous and next pages of results,
+// for the given search parameter ("q" or "acaba").
+function renderPagination(parameter, value, page, perPage, total) {
+  const hasNext = page * perPage < total;
+  if (page === 1 && !hasNext) {
+    return "";
+  }
+  const pageURL = (n) =>
+    `/cerca?${parameter}=${encodeURIComponent(value)}&amp;page=${n}`;
+  let html = '<nav class="pagination">';
+  if (page > 1) {
+    html += `<a href="${pageURL(page - 1)}" rel="prev">Anterior</a>`;
+  }
+  html += `<span>Pàgina ${page}</span>`;
+  if (hasNext) {
+    html += `<a href="${pageURL(page + 1)}" rel="next">Següent</a>`;
+  }
+  return `${html}</nav>`;
+}
+
+async function render(query, page) {
+  const clauses = parseQuery(query);
+  if (clauses.length === 0) {
+    return;
+  }
+  resultsElement.setAttribute("aria-busy", "true");
+
+  const [entries, results, synonyms] = await Promise.all([
+    fetchJSON(`${INDEX_PATH}/lemes.json`),
+    search(clauses),
+    renderSynonyms(query),
+  ]);
+  const quoted = `«${escapeHTML(query)}»`;
+  const pageResults = results.slice((page - 1) * PER_PAGE, page * PER_PAGE);
+
+  let html = renderTitleMatches(entries, query) + synonyms;
+  if (results.length === 0) {
+    html += `<p>Cap lema no conté ${quoted}.</p>`;
+  } else {
+    html += `<p>${results.length} ${results.length === 1 ? "lema conté" : "lemes contenen"} ${quoted}.</p>`;
+    html += (
+      await Promise.all(pageResults.map((result) => renderResult(entries, result)))
+    ).join("");
+    html += renderPagination("q", query, page, PER_PAGE, results.length);
+  }
+
+  resultsElement.innerHTML = html;
+  resultsElement.removeAttribute("aria-busy");
+  document.title = `Cerca: ${query} - DIRELEX`;
+}
+
+// parseSuffixPattern returns the characters of a normalized suffix pattern, reversed,
+// with "**" collapsed, or null if the pattern is over the limits of core.SearchSuffix.
+function parseSuffixPattern(normalized) {
+  const pattern = [...normalized.replace(/\*{2,}/g, "*")];
+  const wildcards = pattern.filter((c) => c === "?" || c === "*").length;
+  if (pattern.length > MAX_SUFFIX_PATTERN_LENGTH || wildcards > MAX_SUFFIX_WILDCARDS) {
+    return null;
+  }
+  return pattern.reverse();
+}
+
+// matchWildcards reports whether a reversed pattern matches the start of a reversed
+// word, as core.matchWildcards: on a mismatch, it backtracks to the last "*" only,
+// so that the matching is linear in the length of the word for each "*".
+function matchWildcards(pattern, word) {
+  let p = 0;
+  let w = 0;
+  let star = -1; // Position of the last "*" in the pattern
+  let mark = 0; // Position of the word when the last "*" was reached
+  while (p < pattern.length) {
+    if (pattern[p] === "*") {
+      star = p;
+      mark = w;
+      p++;
+    } else if (w < word.length && (pattern[p] === "?" || pattern[p] === word[w])) {
+      p++;
+      w++;
+    } else if (star >= 0 && mark < word.length) {
+      mark++;
+      p = star + 1;
+      w = mark;
+    } else {
+      return false;
+    }
+  }
+  return true;
+}
+
+async function renderSuffix(pattern, page) {
+  const normalized = normalize(pattern.trim().replaceAll("_", " "));
+  if (normalized === "") {
+    return;
+  }
+  const reversed = parseSuffixPattern(normalized);
+  if (reversed === null) {
+    resultsElement.innerHTML = `<p>${SUFFIX_LIMITS_MESSAGE}</p>`;
+    return;
+  }
+  resultsElement.setAttribute("aria-busy", "true");
+
+  const words = await fetchJSON(`${INDEX_PATH}/paraules.json`);
+  const results = words.filter((word) =>
+    matchWildcards(reversed, [...normalize(word.word.replaceAll("_", " "))].reverse()),
+  );
+  const quoted = `«${escapeHTML(pattern)}»`;
+
+  let html;
+  if (results.length === 0) {
+    html = `<p>Cap paraula no acaba en ${quoted}.</p>`;
+  } else {
+    html = `<p>${results.length} ${results.length === 1 ? "paraula acaba" : "paraules acaben"} en ${quoted}.</p><ul class="entries">`;
+    for (const word of results.slice(
+      (page - 1) * WORD_LIST_PER_PAGE,
+      page * WORD_LIST_PER_PAGE,
+    )) {
+      const note =
+        word.kind === "synonym" ? ' <span class="no-bold">(sinònim)</span>' : "";
+      html += `<li><a href="${word.url}">${escapeHTML(word.word)}</a>${note}</li>`;
+    }
+    html += "</ul>";
+    html += renderPagination("acaba", pattern, page, WORD_LIST_PER_PAGE, results.length);
+  }
+
+  resultsElement.innerHTML = html;
+  resultsElement.removeAttribute("aria-busy");
+  document.title = `Paraules acabades en ${pattern} - DIRELEX`;
+}
+
+function showError(error) {
+  resultsElement.removeAttribute("aria-busy");
+  resultsElement.innerHTML =
+    "<p>No s'ha pogut fer la cerca. Torneu-ho a provar més tard.</p>";
+  console.error(error);
+}
+
+const params = new URLSearchParams(window.location.search);
+const query = params.get("q") || "";
+const suffix = params.get("acaba") || "";
+const page = Math.max(1, Number.parseInt(params.get("page"), 10) || 1);
+if (query) {
+  searchInput.value = query;
+  render(query, page).catch(showError);
+} else if (suffix) {
+  suffixInput.value = suffix;
+  renderSuffix(suffix, page).catch(showError);
+}
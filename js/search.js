@@ -1,4 +1,4 @@
-import { createAutocomplete } from "./autocomplete.js";
+import { createAutocomplete, entryPath } from "./autocomplete.js";
 import terms from "./data/terms.json" with { type: "json" };
 
 const searchInput = document.querySelector('input[type="search"]');
@@ -13,22 +13,7 @@ const autocompleteInstance = createAutocomplete({
   titleKey: "t",
   onSelect: (entry) => {
     searchInput.value = entry.t.replaceAll("_", " ");
-    // Match Go template's URL encoding behavior:
-    // - Encode: ( ) ' (encodeURIComponent leaves these unescaped)
-    // - Decode: [ ] : (encodeURIComponent encodes these but Go doesn't)
-    // CMS content links may use PHP urlencode output (encodes [ ] :), which still resolves.
-    // Current data slugs include only these reserved characters: [ ] : ( ) ' |.
-    // If slugs ever include other reserved URL characters, keep Go/JS encoders in sync.
-    // Note: Hex digits case differs (JS: uppercase, Go template: lowercase)
-    // but this is functionally equivalent per RFC 3986.
-    const slug = encodeURIComponent(entry.t)
-      .replaceAll("(", "%28")
-      .replaceAll(")", "%29")
-      .replaceAll("'", "%27")
-      .replaceAll("%5B", "[")
-      .replaceAll("%5D", "]")
-      .replaceAll("%3A", ":");
-    window.location.href = `/lema/${slug}`;
+    window.location.href = entryPath(entry.t);
   },
   enableTextSelect: isMobile,
 });